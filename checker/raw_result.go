@@ -305,6 +305,8 @@ type SecurityPolicyData struct {
 type BinaryArtifactData struct {
 	// Files contains a list of files.
 	Files []File
+	// IgnoredFiles contains binaries excluded from the check by configuration.
+	IgnoredFiles []File
 }
 
 // SignedReleasesData contains the raw results
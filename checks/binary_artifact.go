@@ -57,6 +57,16 @@ func BinaryArtifacts(c *checker.CheckRequest) checker.CheckResult {
 		return checker.CreateRuntimeErrorResult(CheckBinaryArtifacts, e)
 	}
 
+	for i := range rawData.IgnoredFiles {
+		f := &rawData.IgnoredFiles[i]
+		c.Dlogger.Info(&checker.LogMessage{
+			Path:   f.Path,
+			Type:   f.Type,
+			Offset: f.Offset,
+			Text:   "binary ignored by configuration",
+		})
+	}
+
 	ret := evaluation.BinaryArtifacts(CheckBinaryArtifacts, findings, c.Dlogger)
 	ret.Findings = findings
 	return ret
@@ -23,6 +23,7 @@ import (
 	"github.com/golang/mock/gomock"
 
 	"github.com/ossf/scorecard/v5/checker"
+	"github.com/ossf/scorecard/v5/checks/raw"
 	mockrepo "github.com/ossf/scorecard/v5/clients/mockclients"
 	scut "github.com/ossf/scorecard/v5/utests"
 )
@@ -99,3 +100,68 @@ func TestBinaryArtifacts(t *testing.T) {
 		})
 	}
 }
+
+//nolint:paralleltest // Since t.Setenv is used.
+func TestBinaryArtifacts_ignored(t *testing.T) {
+	const inputFolder = "testdata/binaryartifacts/jars"
+	tests := []struct {
+		name     string
+		ignore   string
+		expected scut.TestReturn
+	}{
+		{
+			name: "no ignore patterns",
+			expected: scut.TestReturn{
+				Score:        8,
+				NumberOfInfo: 0,
+				NumberOfWarn: 2,
+			},
+		},
+		{
+			name:   "ignored binaries are logged, not scored",
+			ignore: "aws-*.jar",
+			expected: scut.TestReturn{
+				Score:        9,
+				NumberOfInfo: 1,
+				NumberOfWarn: 1,
+			},
+		},
+		{
+			name:   "all binaries ignored",
+			ignore: "*.jar",
+			expected: scut.TestReturn{
+				Score:        checker.MaxResultScore,
+				NumberOfInfo: 2,
+				NumberOfWarn: 0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(raw.EnvVarIgnoreBinaries, tt.ignore)
+			ctrl := gomock.NewController(t)
+			mockRepoClient := mockrepo.NewMockRepoClient(ctrl)
+			mockRepoClient.EXPECT().ListFiles(gomock.Any()).DoAndReturn(func(predicate func(string) (bool, error)) ([]string, error) {
+				var files []string
+				dirFiles, err := os.ReadDir(inputFolder)
+				for _, file := range dirFiles {
+					files = append(files, file.Name())
+				}
+				return files, err
+			}).AnyTimes()
+			mockRepoClient.EXPECT().GetFileReader(gomock.Any()).DoAndReturn(func(file string) (io.ReadCloser, error) {
+				return os.Open("./" + inputFolder + "/" + file)
+			}).AnyTimes()
+
+			dl := scut.TestDetailLogger{}
+			req := checker.CheckRequest{
+				Ctx:        context.Background(),
+				RepoClient: mockRepoClient,
+				Dlogger:    &dl,
+			}
+			result := BinaryArtifacts(&req)
+			scut.ValidateTestReturn(t, tt.name, &tt.expected, &result, &dl)
+		})
+	}
+}
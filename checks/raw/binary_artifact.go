@@ -15,10 +15,14 @@
 package raw
 
 import (
+	"archive/zip"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -33,17 +37,99 @@ import (
 	"github.com/ossf/scorecard/v5/finding"
 )
 
-// how many bytes are considered when determining if a file is text or binary.
-const binaryTestLen = 1024
+const (
+	// how many bytes are considered when determining if a file is text or binary.
+	binaryTestLen = 1024
+	// how many bytes of an archive are read when inspecting its entries.
+	maxArchiveInspectLen = 64 << 20
+)
+
+// Both variables are read from the process environment, so they apply to
+// every repository a process scans. They are intended for single-repo CLI
+// runs and should not be set for the cron or serve modes.
+const (
+	// EnvVarInspectArchives is the environment variable which enables inspecting
+	// the entries of jar archives, so that archives without compiled code or
+	// other binaries (e.g. source or resource-only jars) are not reported as
+	// binary artifacts. Its value is parsed as a boolean, e.g. "true" or "1".
+	// This reads the whole archive, so it is disabled by default.
+	EnvVarInspectArchives = "SCORECARD_BINARY_ARTIFACTS_INSPECT_ARCHIVES"
+	// EnvVarIgnoreBinaries is the environment variable which holds a
+	// comma-separated list of glob patterns (e.g. "*.ttf,assets/icons/*") for
+	// binaries the project does not consider risky. Patterns are matched against
+	// both the file path and its base name. Matching binaries are reported as
+	// ignored instead of counting against the score.
+	EnvVarIgnoreBinaries = "SCORECARD_BINARY_ARTIFACTS_IGNORE"
+)
+
+// binaryFileTypes are the extensions of files, and of archive entries, which
+// are considered binary artifacts.
+var binaryFileTypes = map[string]bool{
+	"crx":    true,
+	"deb":    true,
+	"dex":    true,
+	"dey":    true,
+	"elf":    true,
+	"o":      true,
+	"a":      true,
+	"so":     true,
+	"macho":  true,
+	"iso":    true,
+	"class":  true,
+	"jar":    true,
+	"bundle": true,
+	"dylib":  true,
+	"lib":    true,
+	"msi":    true,
+	"dll":    true,
+	"drv":    true,
+	"efi":    true,
+	"exe":    true,
+	"ocx":    true,
+	"pyc":    true,
+	"pyo":    true,
+	"par":    true,
+	"rpm":    true,
+	"wasm":   true,
+	"whl":    true,
+}
+
+// binaryArtifactsConfig holds the optional behavior of the binary artifact scan.
+type binaryArtifactsConfig struct {
+	ignore          []string
+	inspectArchives bool
+}
+
+func binaryArtifactsConfigFromEnv() (binaryArtifactsConfig, error) {
+	var cfg binaryArtifactsConfig
+	if v := strings.TrimSpace(os.Getenv(EnvVarInspectArchives)); v != "" {
+		inspect, err := strconv.ParseBool(v)
+		if err != nil {
+			return binaryArtifactsConfig{}, sce.WithMessage(sce.ErrScorecardInternal,
+				fmt.Sprintf("invalid %s value %q: %v", EnvVarInspectArchives, v, err))
+		}
+		cfg.inspectArchives = inspect
+	}
+	for _, pattern := range strings.Split(os.Getenv(EnvVarIgnoreBinaries), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			cfg.ignore = append(cfg.ignore, pattern)
+		}
+	}
+	return cfg, nil
+}
 
 // BinaryArtifacts retrieves the raw data for the Binary-Artifacts check.
 func BinaryArtifacts(req *checker.CheckRequest) (checker.BinaryArtifactData, error) {
 	c := req.RepoClient
 	files := []checker.File{}
-	err := fileparser.OnMatchingFileReaderDo(c, fileparser.PathMatcher{
+	cfg, err := binaryArtifactsConfigFromEnv()
+	if err != nil {
+		return checker.BinaryArtifactData{}, err
+	}
+	err = fileparser.OnMatchingFileReaderDo(c, fileparser.PathMatcher{
 		Pattern:       "*",
 		CaseSensitive: false,
-	}, checkBinaryFileReader, &files)
+	}, checkBinaryFileReader, &files, cfg)
 	if err != nil {
 		return checker.BinaryArtifactData{}, fmt.Errorf("%w", err)
 	}
@@ -52,9 +138,52 @@ func BinaryArtifacts(req *checker.CheckRequest) (checker.BinaryArtifactData, err
 	if err != nil {
 		return checker.BinaryArtifactData{}, fmt.Errorf("%w", err)
 	}
+	// Set aside binaries the project has chosen to ignore
+	files, ignored, err := partitionIgnoredBinaries(files, cfg.ignore)
+	if err != nil {
+		return checker.BinaryArtifactData{}, err
+	}
 
 	// No error, return the files.
-	return checker.BinaryArtifactData{Files: files}, nil
+	return checker.BinaryArtifactData{Files: files, IgnoredFiles: ignored}, nil
+}
+
+// partitionIgnoredBinaries splits files into those which count as binary
+// artifacts and those matching one of the ignore patterns.
+func partitionIgnoredBinaries(files []checker.File, patterns []string) (kept, ignored []checker.File, err error) {
+	if len(patterns) == 0 {
+		return files, nil, nil
+	}
+	kept = []checker.File{}
+	for i := range files {
+		var match bool
+		match, err = matchesAnyPattern(files[i].Path, patterns)
+		if err != nil {
+			return nil, nil, err
+		}
+		if match {
+			ignored = append(ignored, files[i])
+		} else {
+			kept = append(kept, files[i])
+		}
+	}
+	return kept, ignored, nil
+}
+
+func matchesAnyPattern(path string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		for _, name := range []string{path, filepath.Base(path)} {
+			match, err := filepath.Match(pattern, name)
+			if err != nil {
+				return false, sce.WithMessage(sce.ErrScorecardInternal,
+					fmt.Sprintf("invalid %s pattern %q: %v", EnvVarIgnoreBinaries, pattern, err))
+			}
+			if match {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
 }
 
 // excludeValidatedGradleWrappers returns the subset of files not confirmed
@@ -87,44 +216,19 @@ func excludeValidatedGradleWrappers(c clients.RepoClient, files []checker.File)
 var checkBinaryFileReader fileparser.DoWhileTrueOnFileReader = func(path string, reader io.Reader,
 	args ...interface{},
 ) (bool, error) {
-	if len(args) != 1 {
+	if len(args) != 2 {
 		return false, fmt.Errorf(
-			"checkBinaryFileReader requires exactly one argument: %w", errInvalidArgLength)
+			"checkBinaryFileReader requires exactly 2 arguments: %w", errInvalidArgLength)
 	}
 	pfiles, ok := args[0].(*[]checker.File)
 	if !ok {
 		return false, fmt.Errorf(
 			"checkBinaryFileReader requires argument of type *[]checker.File: %w", errInvalidArgType)
 	}
-
-	binaryFileTypes := map[string]bool{
-		"crx":    true,
-		"deb":    true,
-		"dex":    true,
-		"dey":    true,
-		"elf":    true,
-		"o":      true,
-		"a":      true,
-		"so":     true,
-		"macho":  true,
-		"iso":    true,
-		"class":  true,
-		"jar":    true,
-		"bundle": true,
-		"dylib":  true,
-		"lib":    true,
-		"msi":    true,
-		"dll":    true,
-		"drv":    true,
-		"efi":    true,
-		"exe":    true,
-		"ocx":    true,
-		"pyc":    true,
-		"pyo":    true,
-		"par":    true,
-		"rpm":    true,
-		"wasm":   true,
-		"whl":    true,
+	cfg, ok := args[1].(binaryArtifactsConfig)
+	if !ok {
+		return false, fmt.Errorf(
+			"checkBinaryFileReader requires argument of type binaryArtifactsConfig: %w", errInvalidArgType)
 	}
 
 	content, err := io.ReadAll(io.LimitReader(reader, binaryTestLen))
@@ -140,28 +244,56 @@ var checkBinaryFileReader fileparser.DoWhileTrueOnFileReader = func(path string,
 		return false, sce.WithMessage(sce.ErrScorecardInternal, fmt.Sprintf("filetype.Get:%v", err))
 	}
 
+	ext := strings.ReplaceAll(filepath.Ext(path), ".", "")
 	exists1 := binaryFileTypes[t.Extension]
-	if exists1 {
-		*pfiles = append(*pfiles, checker.File{
-			Path:   path,
-			Type:   finding.FileTypeBinary,
-			Offset: checker.OffsetDefault,
-		})
+	exists2 := binaryFileTypes[ext]
+	if !exists1 && (isText(content) || !exists2) {
 		return true, nil
 	}
 
-	exists2 := binaryFileTypes[strings.ReplaceAll(filepath.Ext(path), ".", "")]
-	if !isText(content) && exists2 {
-		*pfiles = append(*pfiles, checker.File{
-			Path:   path,
-			Type:   finding.FileTypeBinary,
-			Offset: checker.OffsetDefault,
-		})
+	if cfg.inspectArchives && (t.Extension == "jar" || ext == "jar") {
+		compiled, err := jarContainsCompiledCode(io.MultiReader(bytes.NewReader(content), reader))
+		if err != nil {
+			return false, err
+		}
+		if !compiled {
+			return true, nil
+		}
 	}
 
+	*pfiles = append(*pfiles, checker.File{
+		Path:   path,
+		Type:   finding.FileTypeBinary,
+		Offset: checker.OffsetDefault,
+	})
 	return true, nil
 }
 
+// jarContainsCompiledCode reports whether the jar read from r contains any
+// entry which is itself a binary artifact, e.g. compiled classes, nested jars
+// or native libraries. Archives which are too large to inspect, or which can't
+// be parsed, are assumed to contain compiled code.
+func jarContainsCompiledCode(r io.Reader) (bool, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxArchiveInspectLen+1))
+	if err != nil {
+		return false, fmt.Errorf("reading archive: %w", err)
+	}
+	if len(content) > maxArchiveInspectLen {
+		return true, nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return true, nil //nolint:nilerr // unparseable archives are treated as binaries
+	}
+	for _, f := range zr.File {
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(f.Name)), ".")
+		if binaryFileTypes[ext] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // determines if the first binaryTestLen bytes are text
 //
 //	A version of golang.org/x/tools/godoc/util modified to allow carriage returns
@@ -306,3 +306,160 @@ func TestBinaryArtifacts_workflow_runs_unsupported(t *testing.T) {
 		t.Errorf("expected 1 file, got %d", len(got.Files))
 	}
 }
+
+//nolint:paralleltest // Since t.Setenv is used.
+func TestBinaryArtifacts_inspectArchives(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		inspect string
+		expect  int
+		wantErr bool
+	}{
+		{
+			name:   "source jar without inspection",
+			file:   "../testdata/binaryartifacts/archives/example-sources.jar",
+			expect: 1,
+		},
+		{
+			name:    "source jar with inspection",
+			file:    "../testdata/binaryartifacts/archives/example-sources.jar",
+			inspect: "1",
+			expect:  0,
+		},
+		{
+			name:    "source jar with inspection disabled",
+			file:    "../testdata/binaryartifacts/archives/example-sources.jar",
+			inspect: "false",
+			expect:  1,
+		},
+		{
+			name:    "compiled jar with inspection",
+			file:    "../testdata/binaryartifacts/jars/aws-java-sdk-core-1.11.571.jar",
+			inspect: "true",
+			expect:  1,
+		},
+		{
+			name:    "jar with native library",
+			file:    "../testdata/binaryartifacts/archives/native-payload.jar",
+			inspect: "true",
+			expect:  1,
+		},
+		{
+			name:    "jar with dex file",
+			file:    "../testdata/binaryartifacts/archives/dex-payload.jar",
+			inspect: "true",
+			expect:  1,
+		},
+		{
+			name:    "non-jar binary with inspection",
+			file:    "../testdata/binaryartifacts/wasms/simple.wasm",
+			inspect: "true",
+			expect:  1,
+		},
+		{
+			name:    "invalid value",
+			file:    "../testdata/binaryartifacts/archives/example-sources.jar",
+			inspect: "sometimes",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvVarInspectArchives, tt.inspect)
+			ctrl := gomock.NewController(t)
+			mockRepoClient := mockrepo.NewMockRepoClient(ctrl)
+			mockRepoClient.EXPECT().ListFiles(gomock.Any()).Return([]string{tt.file}, nil).AnyTimes()
+			mockRepoClient.EXPECT().GetFileReader(tt.file).DoAndReturn(func(file string) (io.ReadCloser, error) {
+				return os.Open(file)
+			}).AnyTimes()
+
+			dl := scut.TestDetailLogger{}
+			c := &checker.CheckRequest{
+				RepoClient: mockRepoClient,
+				Repo:       mockrepo.NewMockRepo(ctrl),
+				Dlogger:    &dl,
+			}
+			got, err := BinaryArtifacts(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BinaryArtifacts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got.Files) != tt.expect {
+				t.Errorf("expected %d files, got %d", tt.expect, len(got.Files))
+			}
+		})
+	}
+}
+
+//nolint:paralleltest // Since t.Setenv is used.
+func TestBinaryArtifacts_ignore(t *testing.T) {
+	const (
+		wasm = "../testdata/binaryartifacts/wasms/simple.wasm"
+		jar  = "../testdata/binaryartifacts/jars/aws-java-sdk-core-1.11.571.jar"
+	)
+	tests := []struct {
+		name          string
+		ignore        string
+		expect        int
+		expectIgnored int
+		wantErr       bool
+	}{
+		{
+			name:   "no ignore patterns",
+			expect: 2,
+		},
+		{
+			name:          "ignore by extension",
+			ignore:        "*.wasm",
+			expect:        1,
+			expectIgnored: 1,
+		},
+		{
+			name:          "ignore by path",
+			ignore:        "../testdata/binaryartifacts/jars/*, *.ttf",
+			expect:        1,
+			expectIgnored: 1,
+		},
+		{
+			name:          "ignore everything",
+			ignore:        "*.wasm,*.jar",
+			expect:        0,
+			expectIgnored: 2,
+		},
+		{
+			name:    "invalid pattern",
+			ignore:  "[",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvVarIgnoreBinaries, tt.ignore)
+			ctrl := gomock.NewController(t)
+			mockRepoClient := mockrepo.NewMockRepoClient(ctrl)
+			mockRepoClient.EXPECT().ListFiles(gomock.Any()).Return([]string{wasm, jar}, nil)
+			mockRepoClient.EXPECT().GetFileReader(gomock.Any()).DoAndReturn(func(file string) (io.ReadCloser, error) {
+				return os.Open(file)
+			}).Times(2)
+
+			dl := scut.TestDetailLogger{}
+			c := &checker.CheckRequest{
+				RepoClient: mockRepoClient,
+				Repo:       mockrepo.NewMockRepo(ctrl),
+				Dlogger:    &dl,
+			}
+			got, err := BinaryArtifacts(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BinaryArtifacts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got.Files) != tt.expect {
+				t.Errorf("expected %d files, got %d", tt.expect, len(got.Files))
+			}
+			if len(got.IgnoredFiles) != tt.expectIgnored {
+				t.Errorf("expected %d ignored files, got %d", tt.expectIgnored, len(got.IgnoredFiles))
+			}
+		})
+	}
+}
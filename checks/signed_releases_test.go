@@ -15,7 +15,6 @@
 package checks
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -448,29 +447,26 @@ func TestSignedRelease(t *testing.T) {
 			).MinTimes(1)
 
 			mockRepo := mockrepo.NewMockRepo(ctrl)
-			mockRepo.EXPECT().Host().DoAndReturn(
-				func() string {
-					return ""
-				},
-			).AnyTimes()
-			mockRepo.EXPECT().Path().DoAndReturn(
-				func() string {
-					return ""
-				},
-			).AnyTimes()
+			mockRepo.EXPECT().Host().Return("github.com").AnyTimes()
+			mockRepo.EXPECT().Path().Return("ossf/scorecard").AnyTimes()
 
-			mockPkgC := mockrepo.NewMockProjectPackageClient(ctrl)
-			mockPkgC.EXPECT().GetProjectPackageVersions(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
-				func(ctx context.Context, host, project string) (*packageclient.ProjectPackageVersions, error) {
-					v := packageclient.ProjectPackageVersions{}
-					return &v, nil
+			pkgC := mockrepo.NewFakeProjectPackageClient(map[packageclient.Project][]packageclient.PackageVersion{
+				{Host: "github.com", Path: "ossf/scorecard"}: {
+					{
+						VersionKey: packageclient.VersionKey{
+							System:  "GO",
+							Name:    "github.com/ossf/scorecard/v5",
+							Version: "v5.0.0",
+						},
+						RelationType: "SOURCE_REPO",
+					},
 				},
-			).AnyTimes()
+			})
 
 			req := checker.CheckRequest{
 				RepoClient:    mockRepoC,
 				Repo:          mockRepo,
-				ProjectClient: mockPkgC,
+				ProjectClient: pkgC,
 			}
 			req.Dlogger = &scut.TestDetailLogger{}
 			res := SignedReleases(&req)
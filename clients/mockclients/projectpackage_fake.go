@@ -0,0 +1,56 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockrepo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ossf/scorecard/v5/internal/packageclient"
+)
+
+type fakeProjectPackageClient struct {
+	projects map[string][]packageclient.PackageVersion
+}
+
+// NewFakeProjectPackageClient returns an in-memory ProjectPackageClient for
+// tests, which serves the package versions of each project in projects.
+// Projects that aren't listed are reported as ErrProjNotFoundInDepsDev, and
+// projects listed without versions as ErrEmptyResponse, like the deps.dev client.
+// Projects are matched by packageclient.ProjectKey, as deps.dev does.
+func NewFakeProjectPackageClient(
+	projects map[packageclient.Project][]packageclient.PackageVersion,
+) packageclient.ProjectPackageClient {
+	f := fakeProjectPackageClient{projects: map[string][]packageclient.PackageVersion{}}
+	for p, versions := range projects {
+		f.projects[packageclient.ProjectKey(p.Host, p.Path)] = versions
+	}
+	return f
+}
+
+func (f fakeProjectPackageClient) GetProjectPackageVersions(
+	ctx context.Context, host, project string,
+) (*packageclient.ProjectPackageVersions, error) {
+	versions, ok := f.projects[packageclient.ProjectKey(host, project)]
+	if !ok {
+		return nil, fmt.Errorf("fake GetProjectPackageVersions: %w", packageclient.ErrProjNotFoundInDepsDev)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("fake GetProjectPackageVersions: %w", packageclient.ErrEmptyResponse)
+	}
+	// copy, so callers can't modify the fixtures
+	return &packageclient.ProjectPackageVersions{Versions: slices.Clone(versions)}, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockrepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ossf/scorecard/v5/internal/packageclient"
+)
+
+func TestFakeProjectPackageClient(t *testing.T) {
+	t.Parallel()
+	scorecard := packageclient.Project{Host: "github.com", Path: "ossf/scorecard"}
+	unpublished := packageclient.Project{Host: "github.com", Path: "ossf/unpublished"}
+	client := NewFakeProjectPackageClient(map[packageclient.Project][]packageclient.PackageVersion{
+		scorecard: {
+			{
+				VersionKey:   packageclient.VersionKey{System: "GO", Name: "github.com/ossf/scorecard/v5", Version: "v5.0.0"},
+				RelationType: "SOURCE_REPO",
+			},
+		},
+		unpublished: nil,
+	})
+
+	got, err := client.GetProjectPackageVersions(context.Background(), scorecard.Host, scorecard.Path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(got.Versions))
+	}
+	v := got.Versions[0]
+	if v.VersionKey.System != "GO" || v.VersionKey.Name != "github.com/ossf/scorecard/v5" ||
+		v.VersionKey.Version != "v5.0.0" || v.RelationType != "SOURCE_REPO" {
+		t.Errorf("unexpected version: %+v", v)
+	}
+
+	// lookups are normalized like the deps.dev client's
+	if _, err := client.GetProjectPackageVersions(context.Background(), "GitHub.com", "OSSF/Scorecard.git"); err != nil {
+		t.Errorf("expected a normalized lookup to succeed, got %v", err)
+	}
+
+	_, err = client.GetProjectPackageVersions(context.Background(), unpublished.Host, unpublished.Path)
+	if !errors.Is(err, packageclient.ErrEmptyResponse) {
+		t.Errorf("expected ErrEmptyResponse, got %v", err)
+	}
+	_, err = client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/missing")
+	if !errors.Is(err, packageclient.ErrProjNotFoundInDepsDev) {
+		t.Errorf("expected ErrProjNotFoundInDepsDev, got %v", err)
+	}
+}
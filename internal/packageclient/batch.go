@@ -0,0 +1,134 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sclog "github.com/ossf/scorecard/v5/log"
+)
+
+// DefaultBatchMaxConsecutiveFailures is how many lookups in a row may fail
+// before GetProjectPackageVersionsBatch assumes deps.dev is unavailable.
+const DefaultBatchMaxConsecutiveFailures = 5
+
+// BatchOption configures GetProjectPackageVersionsBatch.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	logger                 *sclog.Logger
+	maxConsecutiveFailures int
+}
+
+// WithBatchMaxConsecutiveFailures sets how many lookups in a row may fail
+// before the remaining projects are skipped. A value of zero or less never
+// skips. It defaults to DefaultBatchMaxConsecutiveFailures.
+func WithBatchMaxConsecutiveFailures(failures int) BatchOption {
+	return func(c *batchConfig) {
+		c.maxConsecutiveFailures = failures
+	}
+}
+
+// WithBatchLogger logs the aggregated error once when projects are skipped
+// after consecutive failures. Without a logger, callers must log the
+// returned error themselves.
+func WithBatchLogger(logger *sclog.Logger) BatchOption {
+	return func(c *batchConfig) {
+		c.logger = logger
+	}
+}
+
+// ErrCircuitOpen is recorded for the projects GetProjectPackageVersionsBatch
+// skipped after too many consecutive failures.
+var ErrCircuitOpen = errors.New("skipped: deps.dev unavailable")
+
+// Project identifies a project by its host and path,
+// e.g. "github.com" and "ossf/scorecard".
+type Project struct {
+	Host string
+	Path string
+}
+
+// ProjectPackageVersionsResult holds the outcome of looking up a single project.
+type ProjectPackageVersionsResult struct {
+	Versions *ProjectPackageVersions
+	Err      error
+}
+
+// GetProjectPackageVersionsBatch looks up the package versions of every project.
+// A failed lookup is recorded in that project's result and doesn't stop the
+// others, unless DefaultBatchMaxConsecutiveFailures (or the number set with
+// WithBatchMaxConsecutiveFailures) lookups fail in a row. The
+// remaining projects are then skipped with ErrCircuitOpen, and an error wrapping
+// ErrCircuitOpen and the last failure is returned. Not-found and empty responses
+// are answers from deps.dev, so they don't count as failures. An error is also
+// returned if ctx is done before every project has been looked up. In both
+// cases the results gathered so far are still returned.
+func GetProjectPackageVersionsBatch(
+	ctx context.Context, c ProjectPackageClient, projects []Project, opts ...BatchOption,
+) (map[Project]ProjectPackageVersionsResult, error) {
+	cfg := batchConfig{maxConsecutiveFailures: DefaultBatchMaxConsecutiveFailures}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	circuitOpen := func(failures int) bool {
+		return cfg.maxConsecutiveFailures > 0 && failures >= cfg.maxConsecutiveFailures
+	}
+	results := make(map[Project]ProjectPackageVersionsResult, len(projects))
+	var lastErr error
+	failures := 0
+	for _, p := range projects {
+		if err := ctx.Err(); err != nil {
+			return results, fmt.Errorf("GetProjectPackageVersionsBatch: %w", err)
+		}
+		if _, ok := results[p]; ok {
+			continue
+		}
+		if circuitOpen(failures) {
+			results[p] = ProjectPackageVersionsResult{Err: ErrCircuitOpen}
+			continue
+		}
+		versions, err := c.GetProjectPackageVersions(ctx, p.Host, p.Path)
+		results[p] = ProjectPackageVersionsResult{
+			Versions: versions,
+			Err:      err,
+		}
+		if isFailure(err) {
+			failures++
+			lastErr = err
+		} else {
+			failures = 0
+		}
+	}
+	if circuitOpen(failures) {
+		err := fmt.Errorf("GetProjectPackageVersionsBatch: %w after %d consecutive failures: %w",
+			ErrCircuitOpen, failures, lastErr)
+		if cfg.logger != nil {
+			cfg.logger.Error(err, "deps.dev lookups skipped")
+		}
+		return results, err
+	}
+	return results, nil
+}
+
+// isFailure reports whether err means deps.dev couldn't answer a lookup, as
+// opposed to answering that it has no data for the project.
+func isFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrProjNotFoundInDepsDev) &&
+		!errors.Is(err, ErrEmptyResponse)
+}
@@ -0,0 +1,197 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+
+	sclog "github.com/ossf/scorecard/v5/log"
+)
+
+type stubProjectClient func(ctx context.Context, host, project string) (*ProjectPackageVersions, error)
+
+func (s stubProjectClient) GetProjectPackageVersions(
+	ctx context.Context, host, project string,
+) (*ProjectPackageVersions, error) {
+	return s(ctx, host, project)
+}
+
+func TestGetProjectPackageVersionsBatch(t *testing.T) {
+	t.Parallel()
+	found := Project{Host: "github.com", Path: "ossf/scorecard"}
+	missing := Project{Host: "github.com", Path: "ossf/missing"}
+	calls := 0
+	client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		calls++
+		if project == missing.Path {
+			return nil, ErrProjNotFoundInDepsDev
+		}
+		return &ProjectPackageVersions{}, nil
+	})
+
+	got, err := GetProjectPackageVersionsBatch(context.Background(), client, []Project{found, missing, found})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 lookups, got %d", calls)
+	}
+	if got[found].Err != nil || got[found].Versions == nil {
+		t.Errorf("unexpected result for %v: %+v", found, got[found])
+	}
+	if !errors.Is(got[missing].Err, ErrProjNotFoundInDepsDev) {
+		t.Errorf("expected ErrProjNotFoundInDepsDev for %v, got %v", missing, got[missing].Err)
+	}
+}
+
+func TestGetProjectPackageVersionsBatch_canceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	first := Project{Host: "github.com", Path: "ossf/first"}
+	second := Project{Host: "github.com", Path: "ossf/second"}
+	client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		cancel()
+		return &ProjectPackageVersions{}, nil
+	})
+
+	got, err := GetProjectPackageVersionsBatch(ctx, client, []Project{first, second})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, ok := got[first]; !ok {
+		t.Errorf("expected partial result for %v", first)
+	}
+	if _, ok := got[second]; ok {
+		t.Errorf("unexpected result for %v", second)
+	}
+}
+
+func TestGetProjectPackageVersionsBatch_circuitBreaker(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client := CreateDepsDevClientWithOptions(WithBaseURL(server.URL), WithMaxAttempts(1))
+
+	var projects []Project
+	for i := 0; i < 2*DefaultBatchMaxConsecutiveFailures; i++ {
+		projects = append(projects, Project{Host: "github.com", Path: fmt.Sprintf("ossf/project%d", i)})
+	}
+	got, err := GetProjectPackageVersionsBatch(context.Background(), client, projects)
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrDepsDevAPI) {
+		t.Errorf("expected ErrCircuitOpen wrapping the last failure, got %v", err)
+	}
+	if n := requests.Load(); n != DefaultBatchMaxConsecutiveFailures {
+		t.Errorf("expected %d requests, got %d", DefaultBatchMaxConsecutiveFailures, n)
+	}
+	if len(got) != len(projects) {
+		t.Fatalf("expected %d results, got %d", len(projects), len(got))
+	}
+	for i, p := range projects {
+		skipped := errors.Is(got[p].Err, ErrCircuitOpen)
+		if want := i >= DefaultBatchMaxConsecutiveFailures; skipped != want {
+			t.Errorf("%v: expected skipped=%t, got %v", p, want, got[p].Err)
+		}
+	}
+}
+
+func TestGetProjectPackageVersionsBatch_notFoundIsNotFailure(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		calls++
+		return nil, ErrProjNotFoundInDepsDev
+	})
+	var projects []Project
+	for i := 0; i < 2*DefaultBatchMaxConsecutiveFailures; i++ {
+		projects = append(projects, Project{Host: "github.com", Path: fmt.Sprintf("ossf/project%d", i)})
+	}
+	if _, err := GetProjectPackageVersionsBatch(context.Background(), client, projects); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls != len(projects) {
+		t.Errorf("expected %d lookups, got %d", len(projects), calls)
+	}
+}
+
+func TestGetProjectPackageVersionsBatch_options(t *testing.T) {
+	t.Parallel()
+	errUnavailable := fmt.Errorf("%w: unavailable", ErrDepsDevAPI)
+	tests := []struct {
+		name        string
+		failures    int
+		wantCalls   int
+		wantLogs    int
+		wantSkipped bool
+	}{
+		{
+			name:        "lower threshold",
+			failures:    2,
+			wantCalls:   2,
+			wantLogs:    1,
+			wantSkipped: true,
+		},
+		{
+			name:      "never skip",
+			failures:  0,
+			wantCalls: 10,
+			wantLogs:  0,
+		},
+	}
+	for _, tt := range tests {
+		calls := 0
+		client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+			calls++
+			return nil, errUnavailable
+		})
+		var logs []string
+		l := funcr.New(func(prefix, args string) {
+			logs = append(logs, args)
+		}, funcr.Options{})
+		var projects []Project
+		for i := 0; i < 10; i++ {
+			projects = append(projects, Project{Host: "github.com", Path: fmt.Sprintf("ossf/project%d", i)})
+		}
+
+		_, err := GetProjectPackageVersionsBatch(context.Background(), client, projects,
+			WithBatchMaxConsecutiveFailures(tt.failures),
+			WithBatchLogger(&sclog.Logger{Logger: &l}),
+		)
+		if skipped := errors.Is(err, ErrCircuitOpen); skipped != tt.wantSkipped {
+			t.Errorf("%s: expected skipped=%t, got %v", tt.name, tt.wantSkipped, err)
+		}
+		if calls != tt.wantCalls {
+			t.Errorf("%s: expected %d lookups, got %d", tt.name, tt.wantCalls, calls)
+		}
+		if len(logs) != tt.wantLogs {
+			t.Fatalf("%s: expected %d log lines, got %d: %v", tt.name, tt.wantLogs, len(logs), logs)
+		}
+		if len(logs) > 0 && !strings.Contains(logs[0], "2 consecutive failures") {
+			t.Errorf("%s: expected the aggregated error to be logged, got %s", tt.name, logs[0])
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	expires  time.Time
+	versions *ProjectPackageVersions
+	err      error
+}
+
+type cachingClient struct {
+	nextSweep time.Time
+	client    ProjectPackageClient
+	now       func() time.Time
+	entries   map[string]cacheEntry
+	ttl       time.Duration
+	mu        sync.Mutex
+}
+
+// WrapWithCache returns a ProjectPackageClient which memoizes the results of
+// client, keyed by ProjectKey(host, project), for ttl. Expired results are
+// evicted, so long-running callers don't accumulate them. A non-positive ttl
+// keeps results for the lifetime of the returned client. Not-found and empty results are
+// cached as well, other errors are not. Results are shared between callers and must not
+// be modified. The returned client is safe for concurrent use.
+func WrapWithCache(client ProjectPackageClient, ttl time.Duration) ProjectPackageClient {
+	return &cachingClient{
+		client:  client,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+func (c *cachingClient) GetProjectPackageVersions(
+	ctx context.Context, host, project string,
+) (*ProjectPackageVersions, error) {
+	key := ProjectKey(host, project)
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && c.expired(entry, c.now()) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return entry.versions, entry.err
+	}
+
+	versions, err := c.client.GetProjectPackageVersions(ctx, host, project)
+	if err != nil && !errors.Is(err, ErrProjNotFoundInDepsDev) && !errors.Is(err, ErrEmptyResponse) {
+		return versions, err //nolint:wrapcheck // errors are passed through unchanged
+	}
+	now := c.now()
+	c.mu.Lock()
+	c.sweep(now)
+	c.entries[key] = cacheEntry{
+		versions: versions,
+		err:      err,
+		expires:  now.Add(c.ttl),
+	}
+	c.mu.Unlock()
+	return versions, err //nolint:wrapcheck // errors are passed through unchanged
+}
+
+func (c *cachingClient) expired(entry cacheEntry, now time.Time) bool {
+	return c.ttl > 0 && !now.Before(entry.expires)
+}
+
+// sweep evicts expired entries which are never looked up again. It runs at
+// most once per ttl, so inserts stay cheap. c.mu must be held.
+func (c *cachingClient) sweep(now time.Time) {
+	if c.ttl <= 0 || now.Before(c.nextSweep) {
+		return
+	}
+	for key, entry := range c.entries {
+		if c.expired(entry, now) {
+			delete(c.entries, key)
+		}
+	}
+	c.nextSweep = now.Add(c.ttl)
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWrapWithCache(t *testing.T) {
+	t.Parallel()
+	errTransient := errors.New("transient")
+	var calls atomic.Int32
+	client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		calls.Add(1)
+		switch project {
+		case "ossf/missing":
+			return nil, ErrProjNotFoundInDepsDev
+		case "ossf/flaky":
+			return nil, errTransient
+		default:
+			return &ProjectPackageVersions{}, nil
+		}
+	})
+	now := time.Now()
+	cache, ok := WrapWithCache(client, time.Minute).(*cachingClient)
+	if !ok {
+		t.Fatal("expected *cachingClient")
+	}
+	cache.now = func() time.Time { return now }
+
+	lookup := func(project string) error {
+		_, err := cache.GetProjectPackageVersions(context.Background(), "github.com", project)
+		return err
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = lookup("ossf/scorecard")
+		}()
+	}
+	wg.Wait()
+	before := calls.Load()
+	if err := lookup("ossf/scorecard"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != before {
+		t.Errorf("expected cached result for ossf/scorecard")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := lookup("ossf/missing"); !errors.Is(err, ErrProjNotFoundInDepsDev) {
+			t.Errorf("expected ErrProjNotFoundInDepsDev, got %v", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := lookup("ossf/flaky"); !errors.Is(err, errTransient) {
+			t.Errorf("expected transient error, got %v", err)
+		}
+	}
+	if got := calls.Load() - before; got != 3 {
+		t.Errorf("expected 3 calls (1 not-found, 2 transient), got %d", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	before = calls.Load()
+	if err := lookup("ossf/scorecard"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != before+1 {
+		t.Errorf("expected expired entry to be fetched again")
+	}
+}
+
+func TestWrapWithCache_projectKey(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		calls.Add(1)
+		return &ProjectPackageVersions{}, nil
+	})
+	cache := WrapWithCache(client, time.Minute)
+	for _, p := range []Project{
+		{Host: "github.com", Path: "ossf/scorecard"},
+		{Host: "GitHub.com", Path: "OSSF/Scorecard"},
+		{Host: "www.github.com", Path: "/ossf/scorecard.git"},
+	} {
+		if _, err := cache.GetProjectPackageVersions(context.Background(), p.Host, p.Path); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected equivalent projects to share one entry, got %d calls", got)
+	}
+}
+
+func TestWrapWithCache_eviction(t *testing.T) {
+	t.Parallel()
+	client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		return &ProjectPackageVersions{}, nil
+	})
+	now := time.Now()
+	cache, ok := WrapWithCache(client, time.Minute).(*cachingClient)
+	if !ok {
+		t.Fatal("expected *cachingClient")
+	}
+	cache.now = func() time.Time { return now }
+	lookup := func(project string) {
+		if _, err := cache.GetProjectPackageVersions(context.Background(), "github.com", project); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	lookup("ossf/a")
+	lookup("ossf/b")
+	now = now.Add(2 * time.Minute)
+	// a fresh insert sweeps both expired entries, though neither is looked up again
+	lookup("ossf/c")
+	if _, ok := cache.entries[ProjectKey("github.com", "ossf/a")]; ok {
+		t.Errorf("expected expired entry for ossf/a to be evicted")
+	}
+	if len(cache.entries) != 1 {
+		t.Errorf("expected 1 cached entry, got %d", len(cache.entries))
+	}
+
+	now = now.Add(2 * time.Minute)
+	// before the next sweep is due, an expired entry is still evicted on lookup
+	cache.nextSweep = now.Add(time.Hour)
+	lookup("ossf/c")
+	if got := cache.entries[ProjectKey("github.com", "ossf/c")].expires; !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the expired entry to be fetched again, expires at %v", got)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// CassetteMode selects whether a Cassette records or replays responses.
+type CassetteMode int
+
+const (
+	// CassetteRecord sends requests to deps.dev and records the responses.
+	CassetteRecord CassetteMode = iota
+	// CassetteReplay serves recorded responses without any network access.
+	CassetteReplay
+)
+
+// ErrCassetteMiss is returned in replay mode for requests that weren't recorded.
+var ErrCassetteMiss = errors.New("request not found in cassette")
+
+// Cassette holds deps.dev responses keyed by request URL, so that a scan can
+// be recorded once and replayed deterministically later.
+type Cassette struct {
+	Responses map[string]RecordedResponse `json:"responses"`
+	mu        sync.Mutex
+}
+
+// RecordedResponse is a single response stored in a Cassette.
+type RecordedResponse struct {
+	Body       string `json:"body"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// NewCassette returns an empty cassette, ready for recording.
+func NewCassette() *Cassette {
+	return &Cassette{Responses: map[string]RecordedResponse{}}
+}
+
+// LoadCassette reads a cassette previously written by Save.
+func LoadCassette(path string) (*Cassette, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	c := NewCassette()
+	if err := json.Unmarshal(content, c); err != nil {
+		return nil, fmt.Errorf("cassette json.Unmarshal: %w", err)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to path as JSON.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette json.Marshal: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+	return nil
+}
+
+// WithCassette records responses to, or replays them from, c depending on mode.
+func WithCassette(c *Cassette, mode CassetteMode) DepsDevOption {
+	return func(cfg *depsDevConfig) {
+		cfg.cassette = c
+		cfg.cassetteMode = mode
+	}
+}
+
+type cassetteTransport struct {
+	base     http.RoundTripper
+	cassette *Cassette
+	mode     CassetteMode
+	// maxBytes caps the recorded body size, like WithMaxResponseBytes.
+	maxBytes int64
+}
+
+func (t *cassetteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	key := r.URL.String()
+	if t.mode == CassetteReplay {
+		t.cassette.mu.Lock()
+		recorded, ok := t.cassette.Responses[key]
+		t.cassette.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCassetteMiss, key)
+		}
+		return recorded.toResponse(r), nil
+	}
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		return nil, fmt.Errorf("cassette RoundTrip: %w", err)
+	}
+	defer resp.Body.Close()
+	var body io.Reader = resp.Body
+	if t.maxBytes > 0 {
+		body = io.LimitReader(resp.Body, t.maxBytes+1)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("resp.Body.Read: %w", err)
+	}
+	if t.maxBytes > 0 && int64(len(b)) > t.maxBytes {
+		// don't record a truncated body
+		return nil, fmt.Errorf("cassette RoundTrip: %w: more than %d bytes", ErrResponseTooLarge, t.maxBytes)
+	}
+	recorded := RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Body:       string(b),
+	}
+	t.cassette.mu.Lock()
+	t.cassette.Responses[key] = recorded
+	t.cassette.mu.Unlock()
+	return recorded.toResponse(r), nil
+}
+
+func (r RecordedResponse) toResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          io.NopCloser(bytes.NewBufferString(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const scorecardVersions = `{"versions":[{"versionKey":{"system":"GO","name":"github.com/ossf/scorecard","version":"v1.0.0"}}]}`
+
+func TestCassette_recordAndReplay(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, scorecardVersions)
+	}))
+	defer server.Close()
+
+	recording := NewCassette()
+	client := &http.Client{Transport: &cassetteTransport{
+		base:     http.DefaultTransport,
+		cassette: recording,
+		mode:     CassetteRecord,
+	}}
+	resp, err := client.Get(server.URL + "/projects")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := recording.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	server.Close()
+
+	loaded, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	got, ok := loaded.Responses[server.URL+"/projects"]
+	if !ok {
+		t.Fatalf("expected recorded response, got %v", loaded.Responses)
+	}
+	if got.StatusCode != http.StatusOK || got.Body != scorecardVersions {
+		t.Errorf("unexpected recorded response: %+v", got)
+	}
+}
+
+func TestCassette_replay(t *testing.T) {
+	t.Parallel()
+	query := "https://api.deps.dev/v3/projects/github.com%2Fossf%2Fscorecard:packageversions"
+	cassette := NewCassette()
+	cassette.Responses[query] = RecordedResponse{StatusCode: http.StatusOK, Body: scorecardVersions}
+	client := CreateDepsDevClientWithOptions(WithCassette(cassette, CassetteReplay))
+
+	versions, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions.Versions) != 1 || versions.Versions[0].VersionKey.Name != "github.com/ossf/scorecard" {
+		t.Errorf("unexpected versions: %+v", versions)
+	}
+
+	_, err = client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/other")
+	if !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("expected ErrCassetteMiss, got %v", err)
+	}
+}
+
+func TestCassette_recordMaxResponseBytes(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("x", 2048))
+	}))
+	defer server.Close()
+
+	cassette := NewCassette()
+	client := CreateDepsDevClientWithOptions(
+		WithBaseURL(server.URL),
+		WithCassette(cassette, CassetteRecord),
+		WithMaxResponseBytes(1024),
+	)
+	_, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+	if len(cassette.Responses) != 0 {
+		t.Errorf("expected oversized response not to be recorded, got %v", cassette.Responses)
+	}
+}
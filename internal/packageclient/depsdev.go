@@ -20,8 +20,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sclog "github.com/ossf/scorecard/v5/log"
 )
 
 // This interface lets Scorecard look up package manager metadata for a project.
@@ -30,74 +38,394 @@ type ProjectPackageClient interface {
 }
 
 type depsDevClient struct {
-	client *http.Client
+	client          *http.Client
+	limiter         *sharedLimiter
+	rand            *lockedRand
+	logger          *sclog.Logger
+	baseURL         string
+	userAgent       string
+	maxAttempts     int
+	retryBaseDelay  time.Duration
+	maxRetryDelay   time.Duration
+	maxRespBytes    int64
+	maxPages        int
+	notFoundRetries int
 }
 
 type ProjectPackageVersions struct {
-	// field alignment
-	//nolint:govet
-	Versions []struct {
-		VersionKey struct {
-			System  string `json:"system"`
-			Name    string `json:"name"`
-			Version string `json:"version"`
-		} `json:"versionKey"`
-		SLSAProvenances []struct {
-			SourceRepository string `json:"sourceRepository"`
-			Commit           string `json:"commit"`
-			Verified         bool   `json:"verified"`
-		} `json:"slsaProvenances"`
-		RelationType       string `json:"relationType"`
-		RelationProvenance string `json:"relationProvenance"`
-	} `json:"versions"`
+	Versions []PackageVersion `json:"versions"`
+}
+
+// PackageVersion is a package version that deps.dev associates with a project.
+type PackageVersion struct {
+	VersionKey         VersionKey       `json:"versionKey"`
+	SLSAProvenances    []SLSAProvenance `json:"slsaProvenances"`
+	RelationType       string           `json:"relationType"`
+	RelationProvenance string           `json:"relationProvenance"`
+}
+
+// VersionKey identifies a version of a package.
+type VersionKey struct {
+	System  string `json:"system"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
 }
 
+// SLSAProvenance describes a SLSA provenance attestation of a package version.
+type SLSAProvenance struct {
+	SourceRepository string `json:"sourceRepository"`
+	Commit           string `json:"commit"`
+	Verified         bool   `json:"verified"`
+}
+
+// CreateDepsDevClient returns a deps.dev client with the default options.
 func CreateDepsDevClient() ProjectPackageClient {
+	return CreateDepsDevClientWithOptions()
+}
+
+// CreateDepsDevClientWithOptions returns a deps.dev client configured with opts.
+func CreateDepsDevClientWithOptions(opts ...DepsDevOption) ProjectPackageClient {
+	cfg := depsDevConfig{
+		baseURL:        DefaultDepsDevBaseURL,
+		userAgent:      DefaultDepsDevUserAgent,
+		maxAttempts:    DefaultDepsDevMaxAttempts,
+		retryBaseDelay: DefaultDepsDevRetryBaseDelay,
+		maxRetryDelay:  DefaultDepsDevMaxRetryDelay,
+		maxPages:       DefaultDepsDevMaxPages,
+		rateLimit:      DefaultDepsDevRateLimit,
+		rateBurst:      DefaultDepsDevRateBurst,
+		maxRespBytes:   DefaultDepsDevMaxResponseBytes,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.backend != nil {
+		return cfg.backend
+	}
 	return depsDevClient{
-		client: &http.Client{},
+		client:          cfg.newHTTPClient(),
+		limiter:         newSharedLimiter(cfg.rateLimit, cfg.rateBurst),
+		rand:            newLockedRand(cfg.randSource),
+		logger:          cfg.logger,
+		baseURL:         cfg.baseURL,
+		userAgent:       cfg.userAgent,
+		maxAttempts:     cfg.maxAttempts,
+		retryBaseDelay:  cfg.retryBaseDelay,
+		maxRetryDelay:   cfg.maxRetryDelay,
+		maxPages:        cfg.maxPages,
+		notFoundRetries: cfg.notFoundRetries,
+		maxRespBytes:    cfg.maxRespBytes,
 	}
 }
 
 var (
 	ErrDepsDevAPI            = errors.New("deps.dev")
 	ErrProjNotFoundInDepsDev = errors.New("project not found in deps.dev")
+	// ErrMaxPagesExceeded is returned when a response spans more pages than
+	// the client is configured to fetch.
+	ErrMaxPagesExceeded = errors.New("deps.dev response exceeds max pages")
+	// ErrResponseTooLarge is returned when a response body exceeds the limit
+	// set with WithMaxResponseBytes.
+	ErrResponseTooLarge = errors.New("deps.dev response too large")
+	// ErrEmptyResponse is returned when deps.dev responds successfully but
+	// without any data, e.g. a project with no published package versions.
+	ErrEmptyResponse = errors.New("empty response from deps.dev")
 )
 
 func (d depsDevClient) GetProjectPackageVersions(
 	ctx context.Context, host, project string,
 ) (*ProjectPackageVersions, error) {
-	path := fmt.Sprintf("%s/%s", host, project)
-	query := fmt.Sprintf("https://api.deps.dev/v3/projects/%s:packageversions", url.QueryEscape(path))
+	key := ProjectKey(host, project)
+	query := fmt.Sprintf("%s/v3/projects/%s:packageversions", d.baseURL, escapePathSegment(key))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, query, nil)
-	if err != nil {
-		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	var res ProjectPackageVersions
+	pageQuery := query
+	for page := 1; ; page++ {
+		var p projectPackageVersionsPage
+		if err := d.getJSON(ctx, pageQuery, ErrProjNotFoundInDepsDev, &p); err != nil {
+			return nil, fmt.Errorf("deps.dev GetProjectPackageVersions: %w", err)
+		}
+		res.Versions = append(res.Versions, p.Versions...)
+		if p.NextPageToken == "" {
+			break
+		}
+		if page >= d.maxPages {
+			return nil, fmt.Errorf("deps.dev GetProjectPackageVersions: %w (%d)", ErrMaxPagesExceeded, d.maxPages)
+		}
+		pageQuery = fmt.Sprintf("%s?pageToken=%s", query, url.QueryEscape(p.NextPageToken))
+	}
+
+	if len(res.Versions) == 0 {
+		return nil, fmt.Errorf("deps.dev GetProjectPackageVersions: %w", ErrEmptyResponse)
+	}
+	return &res, nil
+}
+
+// caseInsensitiveHosts are hosts whose project paths are case-insensitive,
+// and which deps.dev keys in lower case.
+var caseInsensitiveHosts = map[string]bool{
+	"github.com": true,
+	"gitlab.com": true,
+}
+
+// ProjectKey returns the deps.dev project key for a project hosted on host,
+// e.g. "github.com/ossf/scorecard". The host is lower-cased and stripped of a
+// "www." prefix, and the project path of surrounding slashes and a ".git"
+// suffix. Paths on GitHub and GitLab are lower-cased too. Nested paths, such
+// as GitLab subgroups, are kept whole.
+func ProjectKey(host, project string) string {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	project = strings.TrimSuffix(strings.Trim(project, "/"), ".git")
+	if caseInsensitiveHosts[host] {
+		project = strings.ToLower(project)
 	}
+	return host + "/" + project
+}
+
+// escapePathSegment escapes s for use as a single deps.dev path segment.
+// Slashes are encoded as %2F so names like "github.com/ossf/scorecard" or
+// "@colors/colors" stay one segment, and colons are encoded since deps.dev
+// uses them to separate a resource from its method (":packageversions").
+func escapePathSegment(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), ":", "%3A")
+}
+
+// projectPackageVersionsPage is a single page of a GetProjectPackageVersions response.
+type projectPackageVersionsPage struct {
+	ProjectPackageVersions
+	NextPageToken string `json:"nextPageToken"`
+}
 
-	resp, err := d.client.Do(req)
+// getJSON fetches query and decodes the response body into v.
+// A 404 response is reported as notFound.
+func (d depsDevClient) getJSON(ctx context.Context, query string, notFound error, v any) error {
+	resp, err := d.doRequest(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("deps.dev GetProjectPackageVersions: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
-	var res ProjectPackageVersions
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, ErrProjNotFoundInDepsDev
+		return notFound
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %s", ErrDepsDevAPI, resp.Status)
+		return fmt.Errorf("%w: %s%s", ErrDepsDevAPI, resp.Status, errorBody(resp.Body))
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	var r io.Reader = resp.Body
+	if d.maxRespBytes > 0 {
+		// read one byte past the limit to tell a body at the limit from a larger one
+		r = io.LimitReader(resp.Body, d.maxRespBytes+1)
+	}
+	body, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("resp.Body.Read: %w", err)
+		return fmt.Errorf("resp.Body.Read: %w", err)
+	}
+	if d.maxRespBytes > 0 && int64(len(body)) > d.maxRespBytes {
+		return fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, d.maxRespBytes)
 	}
 
-	err = json.Unmarshal(body, &res)
+	err = json.Unmarshal(body, v)
 	if err != nil {
-		return nil, fmt.Errorf("deps.dev json.Unmarshal: %w", err)
+		return fmt.Errorf("deps.dev json.Unmarshal: %w", err)
 	}
 
-	return &res, nil
+	return nil
+}
+
+// maxErrorBodyLen caps how much of a response body is included in errors.
+const maxErrorBodyLen = 512
+
+// errorBody returns the start of an error response body, which usually
+// explains what was wrong with the request, formatted to follow the status.
+func errorBody(body io.Reader) string {
+	b, err := io.ReadAll(io.LimitReader(body, maxErrorBodyLen+1))
+	if err != nil {
+		return ""
+	}
+	truncated := len(b) > maxErrorBodyLen
+	if truncated {
+		b = b[:maxErrorBodyLen]
+	}
+	msg := strings.TrimSpace(strings.ToValidUTF8(string(b), ""))
+	if msg == "" {
+		return ""
+	}
+	if truncated {
+		msg += "..."
+	}
+	return ": " + msg
+}
+
+// doRequest sends a GET request for query, retrying with exponential backoff
+// while deps.dev responds with a transient error status. The last response is
+// returned once the status is no longer retriable or attempts are exhausted.
+// A 404 is only retried if the client is configured with WithNotFoundRetries.
+// Every attempt waits for the client's rate limiter, and retries are logged at
+// debug level. A 429 with Retry-After pauses the limiter, and with it every
+// other request sent through the client.
+func (d depsDevClient) doRequest(ctx context.Context, query string) (*http.Response, error) {
+	notFoundRetries := 0
+	for attempt := 1; ; attempt++ {
+		if err := d.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("waiting for rate limiter: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, query, nil)
+		if err != nil {
+			return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+		}
+		if d.userAgent != "" {
+			req.Header.Set("User-Agent", d.userAgent)
+		}
+		start := time.Now()
+		resp, err := d.client.Do(req)
+		if err != nil {
+			d.logRequest(req, 0, time.Since(start), err)
+			return nil, fmt.Errorf("client.Do: %w", err)
+		}
+		d.logRequest(req, resp.StatusCode, time.Since(start), nil)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			// deps.dev asked every caller to back off, not only this request
+			if pause, ok := retryAfterDelay(d.maxRetryDelay, resp.Header.Get("Retry-After")); ok {
+				d.limiter.PauseFor(pause)
+			}
+		}
+		retry := isRetriable(resp.StatusCode) && attempt < d.maxAttempts
+		if resp.StatusCode == http.StatusNotFound && notFoundRetries < d.notFoundRetries {
+			notFoundRetries++
+			retry = true
+		}
+		if !retry {
+			return resp, nil
+		}
+
+		delay := retryDelay(d.rand, d.retryBaseDelay, d.maxRetryDelay, attempt, resp.Header.Get("Retry-After"))
+		d.logRetry(req, attempt, resp.StatusCode, delay)
+		// drain the body so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("waiting to retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// logRequest logs a single deps.dev request at debug level, if logging is enabled.
+func (d depsDevClient) logRequest(req *http.Request, status int, elapsed time.Duration, err error) {
+	if d.logger == nil {
+		return
+	}
+	kv := []any{
+		"method", req.Method,
+		"url", req.URL.String(),
+		"status", status,
+		"elapsed", elapsed,
+	}
+	if err != nil {
+		kv = append(kv, "error", err.Error())
+	}
+	d.logger.V(1).Info("deps.dev request", kv...)
+}
+
+// logRetry logs a retry of a deps.dev request at debug level, with the
+// attempt that failed, its status and the delay before the next attempt.
+func (d depsDevClient) logRetry(req *http.Request, attempt, status int, delay time.Duration) {
+	if d.logger == nil {
+		return
+	}
+	d.logger.V(1).Info("retrying deps.dev request",
+		"url", req.URL.String(),
+		"attempt", attempt,
+		"status", status,
+		"delay", delay,
+	)
+}
+
+func isRetriable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns how long to wait before the next attempt. A valid
+// Retry-After header takes precedence, otherwise the delay doubles with each
+// attempt, with up to half of it randomized to avoid synchronized retries.
+// Either way the delay is capped at maxDelay, unless maxDelay is not positive.
+func retryDelay(rnd *lockedRand, base, maxDelay time.Duration, attempt int, retryAfter string) time.Duration {
+	if delay, ok := retryAfterDelay(maxDelay, retryAfter); ok {
+		return delay
+	}
+	if maxDelay <= 0 {
+		maxDelay = math.MaxInt64
+	}
+	// double step by step rather than shifting, so large attempts can't overflow
+	delay := min(base, maxDelay)
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		if delay > maxDelay/2 {
+			delay = maxDelay
+			break
+		}
+		delay *= 2
+	}
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rnd.Int63n(half))
+	}
+	return delay
+}
+
+// retryAfterDelay parses a Retry-After header, given in seconds or as a date,
+// into a delay capped at maxDelay, unless maxDelay is not positive.
+func retryAfterDelay(maxDelay time.Duration, retryAfter string) (time.Duration, bool) {
+	if maxDelay <= 0 {
+		maxDelay = math.MaxInt64
+	}
+	if retryAfter == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(retryAfter, 10, 64); err == nil && seconds >= 0 {
+		if seconds > int64(maxDelay/time.Second) {
+			return maxDelay, true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(retryAfter); err == nil {
+		return min(max(time.Until(t), 0), maxDelay), true
+	}
+	return 0, false
+}
+
+// lockedRand is a random source which is safe for concurrent use, since
+// a client is shared between goroutines.
+type lockedRand struct {
+	r  *rand.Rand
+	mu sync.Mutex
+}
+
+// newLockedRand returns a lockedRand drawing from src, or from a time-seeded
+// source if src is nil.
+func newLockedRand(src rand.Source) *lockedRand {
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
+	}
+	//nolint:gosec // jitter doesn't need a cryptographically secure source
+	return &lockedRand{r: rand.New(src)}
+}
+
+func (l *lockedRand) Int63n(n int64) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63n(n)
 }
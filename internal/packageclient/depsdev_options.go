@@ -0,0 +1,328 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	sclog "github.com/ossf/scorecard/v5/log"
+)
+
+const (
+	// DefaultDepsDevBaseURL is the public deps.dev API host.
+	DefaultDepsDevBaseURL = "https://api.deps.dev"
+	// DefaultDepsDevUserAgent identifies Scorecard traffic to deps.dev.
+	DefaultDepsDevUserAgent = "scorecard/v5"
+	// DefaultDepsDevTimeout bounds each deps.dev request unless configured otherwise.
+	DefaultDepsDevTimeout = 30 * time.Second
+	// DefaultDepsDevMaxAttempts is how many times a request is sent when
+	// deps.dev keeps responding with a transient error.
+	DefaultDepsDevMaxAttempts = 3
+	// DefaultDepsDevRetryBaseDelay is the delay before the first retry.
+	DefaultDepsDevRetryBaseDelay = time.Second
+	// DefaultDepsDevMaxRetryDelay caps the delay before a retry, including
+	// delays requested by deps.dev through Retry-After.
+	DefaultDepsDevMaxRetryDelay = 30 * time.Second
+	// DefaultDepsDevMaxPages is how many pages of a paginated response are
+	// fetched before giving up.
+	DefaultDepsDevMaxPages = 20
+	// DefaultDepsDevMaxResponseBytes caps the size of a deps.dev response body.
+	DefaultDepsDevMaxResponseBytes = 16 << 20
+	// DefaultDepsDevRateLimit is how many requests per second are sent to
+	// deps.dev, including retries.
+	DefaultDepsDevRateLimit rate.Limit = 10
+	// DefaultDepsDevRateBurst is how many requests may be sent at once before
+	// DefaultDepsDevRateLimit applies.
+	DefaultDepsDevRateBurst = 10
+)
+
+// DepsDevOption configures the client returned by CreateDepsDevClientWithOptions.
+type DepsDevOption func(*depsDevConfig)
+
+type depsDevConfig struct {
+	backend         ProjectPackageClient
+	httpClient      *http.Client
+	transport       http.RoundTripper
+	randSource      rand.Source
+	tlsConfig       *tls.Config
+	rootCAs         *x509.CertPool
+	proxy           *url.URL
+	cassette        *Cassette
+	logger          *sclog.Logger
+	baseURL         string
+	userAgent       string
+	timeout         time.Duration
+	maxRespBytes    int64
+	rateLimit       rate.Limit
+	rateBurst       int
+	retryBaseDelay  time.Duration
+	maxRetryDelay   time.Duration
+	maxAttempts     int
+	maxPages        int
+	notFoundRetries int
+	cassetteMode    CassetteMode
+}
+
+// WithHTTPClient makes the deps.dev client send requests through a copy of
+// client, keeping its timeout and transport unless they are overridden by
+// WithTimeout or WithTransport.
+func WithHTTPClient(client *http.Client) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.httpClient = client
+	}
+}
+
+// WithBackend makes CreateDepsDevClientWithOptions return backend, such as a
+// depsdevgrpc client, instead of the default REST client. The options which
+// configure the REST client don't apply to backend.
+func WithBackend(backend ProjectPackageClient) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.backend = backend
+	}
+}
+
+// WithTimeout sets the time limit for each deps.dev request.
+// It defaults to DefaultDepsDevTimeout.
+func WithTimeout(timeout time.Duration) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithTransport sets the transport used for deps.dev requests.
+func WithTransport(transport http.RoundTripper) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.transport = transport
+	}
+}
+
+// WithMaxAttempts sets how many times a request is sent when deps.dev responds
+// with 429 or a 5xx gateway/server error. A value of 1 disables retries.
+// It defaults to DefaultDepsDevMaxAttempts.
+func WithMaxAttempts(attempts int) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.maxAttempts = attempts
+	}
+}
+
+// WithRetryBaseDelay sets the delay before the first retry. Later retries
+// back off exponentially, unless deps.dev sends a Retry-After header.
+// It defaults to DefaultDepsDevRetryBaseDelay.
+func WithRetryBaseDelay(delay time.Duration) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.retryBaseDelay = delay
+	}
+}
+
+// WithMaxRetryDelay caps the delay before a retry, both for the exponential
+// back off and for delays requested through Retry-After. A value of zero
+// removes the cap. It defaults to DefaultDepsDevMaxRetryDelay.
+func WithMaxRetryDelay(delay time.Duration) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.maxRetryDelay = delay
+	}
+}
+
+// WithRandSource sets the source of randomness, e.g. for the jitter added to
+// retry delays. It defaults to a time-seeded source.
+func WithRandSource(src rand.Source) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.randSource = src
+	}
+}
+
+// WithRandSeed is WithRandSource with a source seeded by seed, so that all
+// randomized behavior of the client can be reproduced.
+func WithRandSeed(seed int64) DepsDevOption {
+	return WithRandSource(rand.NewSource(seed))
+}
+
+// WithMaxPages caps how many pages of a paginated response are fetched.
+// Responses with more pages fail with ErrMaxPagesExceeded rather than being
+// silently truncated. It defaults to DefaultDepsDevMaxPages.
+func WithMaxPages(pages int) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.maxPages = pages
+	}
+}
+
+// WithNotFoundRetries retries a 404 response up to retries times, with the same
+// backoff as other retries, before reporting the project as not found. This
+// guards against spurious 404s, which otherwise make a project look unknown to
+// deps.dev. 404s are not retried by default.
+func WithNotFoundRetries(retries int) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.notFoundRetries = retries
+	}
+}
+
+// WithMaxResponseBytes caps the size of a deps.dev response body, so that a
+// misbehaving server or proxy can't exhaust memory. Larger responses fail with
+// ErrResponseTooLarge, and aren't recorded by WithCassette. A non-positive
+// limit disables the check.
+// It defaults to DefaultDepsDevMaxResponseBytes.
+func WithMaxResponseBytes(limit int64) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.maxRespBytes = limit
+	}
+}
+
+// WithRateLimit caps the client at limit requests per second, allowing bursts
+// of up to burst requests. Requests wait for the limiter, or until their
+// context is done. Use rate.Inf to disable rate limiting. Either way, a 429
+// from deps.dev with Retry-After pauses all requests of the client.
+// It defaults to DefaultDepsDevRateLimit and DefaultDepsDevRateBurst.
+func WithRateLimit(limit rate.Limit, burst int) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.rateLimit = limit
+		c.rateBurst = burst
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to deps.dev.
+// This is an advanced option, intended for reaching deps.dev through an
+// internal mirror with a private CA. Setting InsecureSkipVerify disables
+// certificate verification entirely and should only be used for testing.
+// It has no effect if the transport isn't an *http.Transport.
+func WithTLSConfig(config *tls.Config) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.tlsConfig = config
+	}
+}
+
+// WithBaseURL sends deps.dev requests to baseURL instead of
+// DefaultDepsDevBaseURL, e.g. an internal mirror or a test server.
+// The API version is part of each request path, so baseURL should not include it.
+func WithBaseURL(baseURL string) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every deps.dev request.
+// It defaults to DefaultDepsDevUserAgent. An empty userAgent leaves Go's default.
+func WithUserAgent(userAgent string) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithLogger makes the client log every deps.dev request it sends, with its
+// method, URL, status code and elapsed time. Requests are logged at debug
+// level (V(1)), so they only show up when logger is configured for it.
+// Nothing is logged by default.
+func WithLogger(logger *sclog.Logger) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.logger = logger
+	}
+}
+
+// WithRootCAs sets the certificate authorities trusted when connecting to
+// deps.dev, e.g. to reach it through a TLS-inspecting proxy. The pool replaces
+// the system pool, so to trust a CA in addition to the system ones, append it
+// to a copy of x509.SystemCertPool. It is applied on top of WithTLSConfig and
+// works with WithProxy and WithTimeout. Like WithTLSConfig, it has no effect
+// if the transport isn't an *http.Transport.
+func WithRootCAs(pool *x509.CertPool) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.rootCAs = pool
+	}
+}
+
+// WithProxy sends deps.dev requests through proxy. It takes precedence over
+// the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables, which are
+// used otherwise. Like WithTLSConfig, it has no effect if the transport isn't
+// an *http.Transport.
+func WithProxy(proxy *url.URL) DepsDevOption {
+	return func(c *depsDevConfig) {
+		c.proxy = proxy
+	}
+}
+
+// newHTTPClient builds the http.Client described by the config.
+func (cfg *depsDevConfig) newHTTPClient() *http.Client {
+	client := &http.Client{Timeout: DefaultDepsDevTimeout}
+	if cfg.httpClient != nil {
+		c := *cfg.httpClient
+		client = &c
+	}
+	if cfg.timeout > 0 {
+		client.Timeout = cfg.timeout
+	}
+	if cfg.transport != nil {
+		client.Transport = cfg.transport
+	}
+	if client.Transport == nil {
+		// be explicit about reading the proxy from the environment, rather
+		// than relying on whatever http.DefaultTransport is set to
+		if transport, ok := cloneTransport(nil); ok {
+			transport.Proxy = http.ProxyFromEnvironment
+			client.Transport = transport
+		}
+	}
+	if cfg.tlsConfig != nil || cfg.rootCAs != nil || cfg.proxy != nil {
+		if transport, ok := cloneTransport(client.Transport); ok {
+			if cfg.tlsConfig != nil {
+				transport.TLSClientConfig = cfg.tlsConfig
+			}
+			if cfg.rootCAs != nil {
+				tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+				if transport.TLSClientConfig != nil {
+					tlsConfig = transport.TLSClientConfig.Clone()
+				}
+				tlsConfig.RootCAs = cfg.rootCAs
+				transport.TLSClientConfig = tlsConfig
+			}
+			if cfg.proxy != nil {
+				transport.Proxy = http.ProxyURL(cfg.proxy)
+			}
+			client.Transport = transport
+		}
+	}
+	if cfg.cassette != nil {
+		base := client.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		client.Transport = &cassetteTransport{
+			base:     base,
+			cassette: cfg.cassette,
+			mode:     cfg.cassetteMode,
+			maxBytes: cfg.maxRespBytes,
+		}
+	}
+	return client
+}
+
+// cloneTransport returns a copy of rt which can be modified safely, or false
+// if rt isn't an *http.Transport. A nil rt is treated as http.DefaultTransport.
+func cloneTransport(rt http.RoundTripper) (*http.Transport, bool) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, false
+	}
+	return t.Clone(), true
+}
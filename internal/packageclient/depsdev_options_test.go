@@ -0,0 +1,418 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"golang.org/x/time/rate"
+
+	sclog "github.com/ossf/scorecard/v5/log"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func okResponse(r *http.Request) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(scorecardVersions)),
+		Request:    r,
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_transport(t *testing.T) {
+	t.Parallel()
+	var got []string
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		got = append(got, r.URL.String())
+		return okResponse(r), nil
+	})
+
+	tests := []struct {
+		name string
+		opts []DepsDevOption
+	}{
+		{
+			name: "WithTransport",
+			opts: []DepsDevOption{WithTransport(transport)},
+		},
+		{
+			name: "WithHTTPClient",
+			opts: []DepsDevOption{WithHTTPClient(&http.Client{Transport: transport})},
+		},
+	}
+	for _, tt := range tests {
+		got = nil
+		client := CreateDepsDevClientWithOptions(tt.opts...)
+		if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		want := "https://api.deps.dev/v3/projects/github.com%2Fossf%2Fscorecard:packageversions"
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("%s: expected request to %s, got %v", tt.name, want, got)
+		}
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_timeout(t *testing.T) {
+	t.Parallel()
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+	client := CreateDepsDevClientWithOptions(WithTransport(transport), WithTimeout(10*time.Millisecond))
+
+	_, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+	var netErr interface{ Timeout() bool }
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestDepsDevConfig_newHTTPClient(t *testing.T) {
+	t.Parallel()
+	client := (&depsDevConfig{}).newHTTPClient()
+	if client.Timeout != DefaultDepsDevTimeout {
+		t.Errorf("expected default timeout %v, got %v", DefaultDepsDevTimeout, client.Timeout)
+	}
+
+	callerClient := &http.Client{Timeout: time.Minute}
+	client = (&depsDevConfig{httpClient: callerClient}).newHTTPClient()
+	if client == callerClient || client.Timeout != time.Minute {
+		t.Errorf("expected a copy of the caller's client, got %+v", client)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS13}
+	client = (&depsDevConfig{tlsConfig: tlsConfig}).newHTTPClient()
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig != tlsConfig {
+		t.Errorf("expected TLS config to be applied, got %+v", client.Transport)
+	}
+}
+
+func TestDepsDevConfig_newHTTPClient_proxy(t *testing.T) {
+	t.Parallel()
+	req := httptest.NewRequest(http.MethodGet, "https://api.deps.dev/v3/projects", nil)
+
+	client := (&depsDevConfig{}).newHTTPClient()
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Fatalf("expected default transport to read the proxy from the environment, got %+v", client.Transport)
+	}
+
+	proxy := &url.URL{Scheme: "http", Host: "proxy.example.com:3128"}
+	client = (&depsDevConfig{proxy: proxy}).newHTTPClient()
+	transport, ok = client.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Fatalf("expected proxy to be applied, got %+v", client.Transport)
+	}
+	got, err := transport.Proxy(req)
+	if err != nil || got.String() != proxy.String() {
+		t.Errorf("expected proxy %v, got %v (%v)", proxy, got, err)
+	}
+
+	// a custom transport is left alone
+	custom := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r), nil
+	})
+	client = (&depsDevConfig{proxy: proxy, transport: custom}).newHTTPClient()
+	if _, ok := client.Transport.(roundTripperFunc); !ok {
+		t.Errorf("expected custom transport to be kept, got %+v", client.Transport)
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_logger(t *testing.T) {
+	t.Parallel()
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return okResponse(r), nil
+	})
+
+	tests := []struct {
+		name      string
+		verbosity int
+		wantLogs  int
+	}{
+		{
+			name:      "debug",
+			verbosity: 1,
+			wantLogs:  1,
+		},
+		{
+			name:      "info",
+			verbosity: 0,
+			wantLogs:  0,
+		},
+	}
+	for _, tt := range tests {
+		var logs []string
+		l := funcr.New(func(prefix, args string) {
+			logs = append(logs, args)
+		}, funcr.Options{Verbosity: tt.verbosity})
+		client := CreateDepsDevClientWithOptions(
+			WithTransport(transport),
+			WithLogger(&sclog.Logger{Logger: &l}),
+		)
+		if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if len(logs) != tt.wantLogs {
+			t.Fatalf("%s: expected %d log lines, got %d: %v", tt.name, tt.wantLogs, len(logs), logs)
+		}
+		for _, want := range []string{
+			`"method"="GET"`,
+			`"url"="https://api.deps.dev/v3/projects/github.com%2Fossf%2Fscorecard:packageversions"`,
+			`"status"=200`,
+			`"elapsed"=`,
+		} {
+			if len(logs) > 0 && !strings.Contains(logs[0], want) {
+				t.Errorf("%s: expected log to contain %s, got %s", tt.name, want, logs[0])
+			}
+		}
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_retryLogs(t *testing.T) {
+	t.Parallel()
+	var calls int
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Retry-After": []string{"0"}},
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    r,
+			}, nil
+		}
+		return okResponse(r), nil
+	})
+
+	tests := []struct {
+		name      string
+		verbosity int
+		wantLogs  int
+	}{
+		{
+			name:      "debug",
+			verbosity: 1,
+			wantLogs:  1,
+		},
+		{
+			name:      "info",
+			verbosity: 0,
+			wantLogs:  0,
+		},
+	}
+	for _, tt := range tests {
+		calls = 0
+		var logs []string
+		l := funcr.New(func(prefix, args string) {
+			if strings.Contains(args, "retrying deps.dev request") {
+				logs = append(logs, args)
+			}
+		}, funcr.Options{Verbosity: tt.verbosity})
+		client := CreateDepsDevClientWithOptions(
+			WithTransport(transport),
+			WithLogger(&sclog.Logger{Logger: &l}),
+		)
+		if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if len(logs) != tt.wantLogs {
+			t.Fatalf("%s: expected %d retry log lines, got %d: %v", tt.name, tt.wantLogs, len(logs), logs)
+		}
+		for _, want := range []string{
+			`"attempt"=1`,
+			`"status"=503`,
+			`"delay"="0s"`,
+		} {
+			if len(logs) > 0 && !strings.Contains(logs[0], want) {
+				t.Errorf("%s: expected log to contain %s, got %s", tt.name, want, logs[0])
+			}
+		}
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_baseURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+	}{
+		{
+			name:    "mirror",
+			baseURL: "https://deps.example.com/mirror",
+			want:    "https://deps.example.com/mirror/v3/projects/github.com%2Fossf%2Fscorecard:packageversions",
+		},
+		{
+			name:    "trailing slash",
+			baseURL: "https://deps.example.com/",
+			want:    "https://deps.example.com/v3/projects/github.com%2Fossf%2Fscorecard:packageversions",
+		},
+	}
+	for _, tt := range tests {
+		var got string
+		transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			got = r.URL.String()
+			return okResponse(r), nil
+		})
+		client := CreateDepsDevClientWithOptions(WithTransport(transport), WithBaseURL(tt.baseURL))
+		if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected request to %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_rateLimit(t *testing.T) {
+	t.Parallel()
+	var requests int
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return okResponse(r), nil
+	})
+	client := CreateDepsDevClientWithOptions(
+		WithTransport(transport),
+		WithRateLimit(rate.Every(time.Hour), 1),
+	)
+	if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the burst is used up, so the next request must give up with its context
+	// instead of waiting an hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetProjectPackageVersions(ctx, "github.com", "ossf/scorecard"); err == nil {
+		t.Error("expected rate limited request to fail")
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request to be sent, got %d", requests)
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_rootCAs(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, scorecardVersions)
+	}))
+	defer server.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	client := CreateDepsDevClientWithOptions(WithBaseURL(server.URL))
+	if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard"); err == nil {
+		t.Error("expected the self-signed certificate to be rejected")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	client = CreateDepsDevClientWithOptions(
+		WithBaseURL(server.URL),
+		WithTLSConfig(tlsConfig),
+		WithRootCAs(pool),
+		WithTimeout(time.Minute),
+	)
+	if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if tlsConfig.RootCAs != nil {
+		t.Error("expected the caller's TLS config not to be modified")
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_maxResponseBytes(t *testing.T) {
+	t.Parallel()
+	body := scorecardVersions + strings.Repeat(" ", 1024)
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		resp := okResponse(r)
+		resp.Body = io.NopCloser(strings.NewReader(body))
+		return resp, nil
+	})
+
+	tests := []struct {
+		wantErr error
+		name    string
+		limit   int64
+	}{
+		{
+			name:    "oversized",
+			limit:   int64(len(body)) - 1,
+			wantErr: ErrResponseTooLarge,
+		},
+		{
+			name:  "at limit",
+			limit: int64(len(body)),
+		},
+		{
+			name:  "disabled",
+			limit: 0,
+		},
+	}
+	for _, tt := range tests {
+		client := CreateDepsDevClientWithOptions(WithTransport(transport), WithMaxResponseBytes(tt.limit))
+		_, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestCreateDepsDevClientWithOptions_backend(t *testing.T) {
+	t.Parallel()
+	want := &ProjectPackageVersions{
+		Versions: []PackageVersion{
+			{
+				VersionKey: VersionKey{System: "GO", Name: "github.com/ossf/scorecard/v5", Version: "v5.0.0"},
+			},
+		},
+	}
+	backend := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		return want, nil
+	})
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected REST request to %s", r.URL)
+		return okResponse(r), nil
+	})
+
+	client := CreateDepsDevClientWithOptions(WithTransport(transport), WithBackend(backend))
+	got, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("expected the backend's result, got %v", got)
+	}
+}
@@ -0,0 +1,563 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetProjectPackageVersions_retries(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxAttempts  int
+		wantErr      error
+		wantAttempts int32
+	}{
+		{
+			name:         "503 twice then 200",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			maxAttempts:  3,
+			wantAttempts: 3,
+		},
+		{
+			name:         "429 then 200",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			maxAttempts:  3,
+			wantAttempts: 2,
+		},
+		{
+			name:         "attempts exhausted",
+			statuses:     []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			maxAttempts:  2,
+			wantErr:      ErrDepsDevAPI,
+			wantAttempts: 2,
+		},
+		{
+			name:         "404 is not retried",
+			statuses:     []int{http.StatusNotFound, http.StatusOK},
+			maxAttempts:  3,
+			wantErr:      ErrProjNotFoundInDepsDev,
+			wantAttempts: 1,
+		},
+		{
+			name:         "400 is not retried",
+			statuses:     []int{http.StatusBadRequest, http.StatusOK},
+			maxAttempts:  3,
+			wantErr:      ErrDepsDevAPI,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[attempts.Add(1)-1]
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					fmt.Fprint(w, scorecardVersions)
+				}
+			}))
+			defer server.Close()
+
+			client := CreateDepsDevClientWithOptions(
+				WithBaseURL(server.URL),
+				WithMaxAttempts(tt.maxAttempts),
+				WithRetryBaseDelay(time.Millisecond),
+			)
+			versions, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil || len(versions.Versions) != 1 {
+				t.Errorf("unexpected result: %v, %v", versions, err)
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, got)
+			}
+		})
+	}
+}
+
+func TestGetProjectPackageVersions_retryCanceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := CreateDepsDevClientWithOptions(WithBaseURL(server.URL))
+	_, err := client.GetProjectPackageVersions(ctx, "github.com", "ossf/scorecard")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxDelay   time.Duration
+		attempt    int
+		min, max   time.Duration
+	}{
+		{
+			name:    "first retry",
+			attempt: 1,
+			min:     500 * time.Millisecond,
+			max:     time.Second,
+		},
+		{
+			name:    "third retry",
+			attempt: 3,
+			min:     2 * time.Second,
+			max:     4 * time.Second,
+		},
+		{
+			name:       "retry-after seconds",
+			retryAfter: "7",
+			attempt:    1,
+			min:        7 * time.Second,
+			max:        7 * time.Second,
+		},
+		{
+			name:       "retry-after in the past",
+			retryAfter: "Wed, 21 Oct 2015 07:28:00 GMT",
+			attempt:    1,
+			min:        0,
+			max:        0,
+		},
+		{
+			name:       "invalid retry-after",
+			retryAfter: "soon",
+			attempt:    1,
+			min:        500 * time.Millisecond,
+			max:        time.Second,
+		},
+		{
+			name:    "backoff capped",
+			attempt: 10,
+			min:     15 * time.Second,
+			max:     30 * time.Second,
+		},
+		{
+			name:    "shift would overflow",
+			attempt: 100,
+			min:     15 * time.Second,
+			max:     30 * time.Second,
+		},
+		{
+			name:     "shift would overflow without a cap",
+			maxDelay: -1,
+			attempt:  100,
+			min:      math.MaxInt64 / 4,
+			max:      math.MaxInt64,
+		},
+		{
+			name:       "retry-after seconds capped",
+			retryAfter: "3600",
+			attempt:    1,
+			min:        30 * time.Second,
+			max:        30 * time.Second,
+		},
+		{
+			name:       "retry-after seconds overflow",
+			retryAfter: "99999999999999999",
+			attempt:    1,
+			min:        30 * time.Second,
+			max:        30 * time.Second,
+		},
+		{
+			name:       "retry-after date capped",
+			retryAfter: "Fri, 31 Dec 9999 23:59:59 GMT",
+			attempt:    1,
+			min:        30 * time.Second,
+			max:        30 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		maxDelay := tt.maxDelay
+		if maxDelay == 0 {
+			maxDelay = 30 * time.Second
+		}
+		got := retryDelay(newLockedRand(nil), time.Second, maxDelay, tt.attempt, tt.retryAfter)
+		if got < tt.min || got > tt.max {
+			t.Errorf("%s: retryDelay() = %v, want between %v and %v", tt.name, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestRetryDelay_randSeed(t *testing.T) {
+	t.Parallel()
+	delays := func(opt DepsDevOption) []time.Duration {
+		var cfg depsDevConfig
+		opt(&cfg)
+		rnd := newLockedRand(cfg.randSource)
+		var res []time.Duration
+		for attempt := 1; attempt <= 5; attempt++ {
+			res = append(res, retryDelay(rnd, time.Second, time.Minute, attempt, ""))
+		}
+		return res
+	}
+	first, second := delays(WithRandSeed(42)), delays(WithRandSeed(42))
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected the same delays for the same seed, got %v and %v", first, second)
+	}
+	if other := delays(WithRandSeed(7)); reflect.DeepEqual(first, other) {
+		t.Errorf("expected different delays for different seeds, got %v", other)
+	}
+}
+
+func TestGetProjectPackageVersions_pagination(t *testing.T) {
+	t.Parallel()
+	pages := map[string]string{
+		"":       `{"versions":[{"versionKey":{"system":"NPM","name":"a","version":"1.0.0"}}],"nextPageToken":"page 2"}`,
+		"page 2": `{"versions":[{"versionKey":{"system":"NPM","name":"a","version":"2.0.0"}}],"nextPageToken":"page3"}`,
+		"page3":  `{"versions":[{"versionKey":{"system":"GO","name":"b","version":"v1.0.0"}}]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Query().Get("pageToken")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	client := CreateDepsDevClientWithOptions(WithBaseURL(server.URL))
+	versions, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, v := range versions.Versions {
+		got = append(got, v.VersionKey.Version)
+	}
+	want := []string{"1.0.0", "2.0.0", "v1.0.0"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected versions %v, got %v", want, got)
+	}
+
+	client = CreateDepsDevClientWithOptions(WithBaseURL(server.URL), WithMaxPages(2))
+	_, err = client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+	if !errors.Is(err, ErrMaxPagesExceeded) {
+		t.Errorf("expected ErrMaxPagesExceeded, got %v", err)
+	}
+}
+
+func TestGetProjectPackageVersions_empty(t *testing.T) {
+	t.Parallel()
+	for _, body := range []string{`{}`, `{"versions":[]}`} {
+		body := body
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		}))
+		client := CreateDepsDevClientWithOptions(WithBaseURL(server.URL))
+		versions, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+		server.Close()
+		if !errors.Is(err, ErrEmptyResponse) {
+			t.Errorf("%s: expected ErrEmptyResponse, got %v", body, err)
+		}
+		if errors.Is(err, ErrProjNotFoundInDepsDev) {
+			t.Errorf("%s: empty response must be distinct from not-found", body)
+		}
+		if versions != nil {
+			t.Errorf("%s: expected nil versions, got %+v", body, versions)
+		}
+	}
+}
+
+func TestEscapePathSegment(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "project",
+			in:   "github.com/ossf/scorecard",
+			want: "github.com%2Fossf%2Fscorecard",
+		},
+		{
+			name: "scoped npm package",
+			in:   "@colors/colors",
+			want: "@colors%2Fcolors",
+		},
+		{
+			name: "maven coordinates",
+			in:   "com.google.guava:guava",
+			want: "com.google.guava%3Aguava",
+		},
+		{
+			name: "spaces",
+			in:   "gitlab.com/some group/some project",
+			want: "gitlab.com%2Fsome%20group%2Fsome%20project",
+		},
+	}
+	for _, tt := range tests {
+		if got := escapePathSegment(tt.in); got != tt.want {
+			t.Errorf("%s: escapePathSegment(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetProjectPackageVersions_userAgent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want string
+		opts []DepsDevOption
+	}{
+		{
+			name: "default",
+			want: DefaultDepsDevUserAgent,
+		},
+		{
+			name: "WithUserAgent",
+			opts: []DepsDevOption{WithUserAgent("my-scanner/1.0")},
+			want: "my-scanner/1.0",
+		},
+	}
+	for _, tt := range tests {
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("User-Agent")
+			fmt.Fprint(w, scorecardVersions)
+		}))
+		client := CreateDepsDevClientWithOptions(append(tt.opts, WithBaseURL(server.URL))...)
+		_, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected User-Agent %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestProjectPackageVersions_json(t *testing.T) {
+	t.Parallel()
+	const raw = `{"versions":[{"versionKey":{"system":"NPM","name":"@colors/colors","version":"1.6.0"},` +
+		`"slsaProvenances":[{"sourceRepository":"https://github.com/DABH/colors.js","commit":"abc","verified":true}],` +
+		`"relationType":"SOURCE_REPO","relationProvenance":"SLSA_ATTESTATION"}]}`
+	want := ProjectPackageVersions{
+		Versions: []PackageVersion{
+			{
+				VersionKey: VersionKey{System: "NPM", Name: "@colors/colors", Version: "1.6.0"},
+				SLSAProvenances: []SLSAProvenance{
+					{SourceRepository: "https://github.com/DABH/colors.js", Commit: "abc", Verified: true},
+				},
+				RelationType:       "SOURCE_REPO",
+				RelationProvenance: "SLSA_ATTESTATION",
+			},
+		},
+	}
+
+	var got ProjectPackageVersions
+	if err := json.Unmarshal([]byte(raw), &got); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if string(b) != raw {
+		t.Errorf("expected %s, got %s", raw, b)
+	}
+}
+
+func TestGetProjectPackageVersions_errorBody(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "short body",
+			body: `{"code":3,"message":"invalid project key"}` + "\n",
+			want: `deps.dev: 400 Bad Request: {"code":3,"message":"invalid project key"}`,
+		},
+		{
+			name: "truncated body",
+			body: strings.Repeat("x", maxErrorBodyLen+100),
+			want: "deps.dev: 400 Bad Request: " + strings.Repeat("x", maxErrorBodyLen) + "...",
+		},
+		{
+			name: "empty body",
+			body: "",
+			want: "deps.dev: 400 Bad Request",
+		},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, tt.body)
+		}))
+		client := CreateDepsDevClientWithOptions(WithBaseURL(server.URL))
+		_, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+		server.Close()
+		if !errors.Is(err, ErrDepsDevAPI) {
+			t.Fatalf("%s: expected ErrDepsDevAPI, got %v", tt.name, err)
+		}
+		if !strings.HasSuffix(err.Error(), tt.want) {
+			t.Errorf("%s: expected error ending in %q, got %q", tt.name, tt.want, err.Error())
+		}
+	}
+}
+
+func TestProjectKey(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		host    string
+		project string
+		want    string
+		wantURL string
+	}{
+		{
+			name:    "github",
+			host:    "github.com",
+			project: "ossf/scorecard",
+			want:    "github.com/ossf/scorecard",
+			wantURL: "/v3/projects/github.com%2Fossf%2Fscorecard:packageversions",
+		},
+		{
+			name:    "github mixed case",
+			host:    "GitHub.com",
+			project: "/OSSF/Scorecard.git",
+			want:    "github.com/ossf/scorecard",
+			wantURL: "/v3/projects/github.com%2Fossf%2Fscorecard:packageversions",
+		},
+		{
+			name:    "gitlab",
+			host:    "www.gitlab.com",
+			project: "gitlab-org/gitlab",
+			want:    "gitlab.com/gitlab-org/gitlab",
+			wantURL: "/v3/projects/gitlab.com%2Fgitlab-org%2Fgitlab:packageversions",
+		},
+		{
+			name:    "gitlab nested subgroups",
+			host:    "gitlab.com",
+			project: "gitlab-org/security-products/analyzers/gemnasium/",
+			want:    "gitlab.com/gitlab-org/security-products/analyzers/gemnasium",
+			wantURL: "/v3/projects/gitlab.com%2Fgitlab-org%2Fsecurity-products%2Fanalyzers%2Fgemnasium:packageversions",
+		},
+		{
+			name:    "other hosts keep case",
+			host:    "bitbucket.org",
+			project: "Atlassian/Python-Bitbucket",
+			want:    "bitbucket.org/Atlassian/Python-Bitbucket",
+			wantURL: "/v3/projects/bitbucket.org%2FAtlassian%2FPython-Bitbucket:packageversions",
+		},
+	}
+	for _, tt := range tests {
+		if got := ProjectKey(tt.host, tt.project); got != tt.want {
+			t.Errorf("%s: ProjectKey(%q, %q) = %q, want %q", tt.name, tt.host, tt.project, got, tt.want)
+		}
+
+		var gotURL string
+		transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			gotURL = r.URL.EscapedPath()
+			return okResponse(r), nil
+		})
+		client := CreateDepsDevClientWithOptions(WithTransport(transport))
+		if _, err := client.GetProjectPackageVersions(context.Background(), tt.host, tt.project); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if gotURL != tt.wantURL {
+			t.Errorf("%s: expected request to %s, got %s", tt.name, tt.wantURL, gotURL)
+		}
+	}
+}
+
+func TestGetProjectPackageVersions_notFoundRetries(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		wantErr      error
+		name         string
+		notFounds    int32
+		retries      int
+		wantRequests int32
+	}{
+		{
+			name:         "no retries by default",
+			notFounds:    1,
+			wantErr:      ErrProjNotFoundInDepsDev,
+			wantRequests: 1,
+		},
+		{
+			name:         "spurious 404",
+			notFounds:    1,
+			retries:      1,
+			wantRequests: 2,
+		},
+		{
+			name:         "persistent 404",
+			notFounds:    10,
+			retries:      2,
+			wantErr:      ErrProjNotFoundInDepsDev,
+			wantRequests: 3,
+		},
+	}
+	for _, tt := range tests {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) <= tt.notFounds {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, scorecardVersions)
+		}))
+		client := CreateDepsDevClientWithOptions(
+			WithBaseURL(server.URL),
+			WithNotFoundRetries(tt.retries),
+			WithRetryBaseDelay(time.Millisecond),
+		)
+		_, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard")
+		server.Close()
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+		if got := requests.Load(); got != tt.wantRequests {
+			t.Errorf("%s: expected %d requests, got %d", tt.name, tt.wantRequests, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package depsdevgrpc implements packageclient.ProjectPackageClient on top of
+// the deps.dev gRPC API, as an alternative to the default REST client.
+package depsdevgrpc
+
+import (
+	"context"
+	"fmt"
+
+	depsdevpb "deps.dev/api/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/ossf/scorecard/v5/internal/packageclient"
+)
+
+// DefaultTarget is the address of the public deps.dev gRPC API.
+const DefaultTarget = "api.deps.dev:443"
+
+// Client is a packageclient.ProjectPackageClient which holds a gRPC
+// connection. Close releases the connection once the client is no longer used.
+type Client interface {
+	packageclient.ProjectPackageClient
+	Close() error
+}
+
+type client struct {
+	conn     *grpc.ClientConn
+	insights depsdevpb.InsightsClient
+}
+
+// CreateDepsDevGRPCClient returns a client backed by the deps.dev gRPC service
+// at target, e.g. DefaultTarget. Any dial options, such as transport
+// credentials, replace the default of TLS with the system roots. To use it in
+// place of the REST client, pass it to packageclient.WithBackend.
+func CreateDepsDevGRPCClient(target string, opts ...grpc.DialOption) (Client, error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{
+			grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, "")),
+		}
+	}
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("grpc.NewClient: %w", err)
+	}
+	return &client{
+		conn:     conn,
+		insights: depsdevpb.NewInsightsClient(conn),
+	}, nil
+}
+
+func (c *client) Close() error {
+	if err := c.conn.Close(); err != nil {
+		return fmt.Errorf("conn.Close: %w", err)
+	}
+	return nil
+}
+
+func (c *client) GetProjectPackageVersions(
+	ctx context.Context, host, project string,
+) (*packageclient.ProjectPackageVersions, error) {
+	resp, err := c.insights.GetProjectPackageVersions(ctx, &depsdevpb.GetProjectPackageVersionsRequest{
+		ProjectKey: &depsdevpb.ProjectKey{Id: packageclient.ProjectKey(host, project)},
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil, packageclient.ErrProjNotFoundInDepsDev
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: GetProjectPackageVersions: %w", packageclient.ErrDepsDevAPI, err)
+	}
+
+	versions := resp.GetVersions()
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("GetProjectPackageVersions: %w", packageclient.ErrEmptyResponse)
+	}
+	res := packageclient.ProjectPackageVersions{
+		Versions: make([]packageclient.PackageVersion, 0, len(versions)),
+	}
+	for _, v := range versions {
+		out := packageclient.PackageVersion{
+			VersionKey: packageclient.VersionKey{
+				System:  v.GetVersionKey().GetSystem().String(),
+				Name:    v.GetVersionKey().GetName(),
+				Version: v.GetVersionKey().GetVersion(),
+			},
+			RelationType:       v.GetRelationType().String(),
+			RelationProvenance: v.GetRelationProvenance().String(),
+		}
+		for _, p := range v.GetSlsaProvenances() {
+			out.SLSAProvenances = append(out.SLSAProvenances, packageclient.SLSAProvenance{
+				SourceRepository: p.GetSourceRepository(),
+				Commit:           p.GetCommit(),
+				Verified:         p.GetVerified(),
+			})
+		}
+		res.Versions = append(res.Versions, out)
+	}
+	return &res, nil
+}
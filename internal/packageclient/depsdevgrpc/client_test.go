@@ -0,0 +1,169 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package depsdevgrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	depsdevpb "deps.dev/api/v3"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ossf/scorecard/v5/internal/packageclient"
+)
+
+type fakeInsights struct {
+	depsdevpb.UnimplementedInsightsServer
+	projects map[string]*depsdevpb.ProjectPackageVersions
+}
+
+func (f *fakeInsights) GetProjectPackageVersions(
+	ctx context.Context, req *depsdevpb.GetProjectPackageVersionsRequest,
+) (*depsdevpb.ProjectPackageVersions, error) {
+	key := req.GetProjectKey().GetId()
+	if key == "github.com/ossf/unavailable" {
+		return nil, status.Error(codes.Unavailable, "try again later")
+	}
+	resp, ok := f.projects[key]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "project not found")
+	}
+	return resp, nil
+}
+
+// newBufconnClient serves srv over an in-memory listener and returns a
+// client connected to it.
+func newBufconnClient(t *testing.T, srv depsdevpb.InsightsServer) Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	depsdevpb.RegisterInsightsServer(server, srv)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	c, err := CreateDepsDevGRPCClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("CreateDepsDevGRPCClient: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := c.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	return c
+}
+
+func TestGetProjectPackageVersions(t *testing.T) {
+	t.Parallel()
+	c := newBufconnClient(t, &fakeInsights{
+		projects: map[string]*depsdevpb.ProjectPackageVersions{
+			"github.com/ossf/scorecard": {
+				Versions: []*depsdevpb.ProjectPackageVersions_Version{
+					{
+						VersionKey: &depsdevpb.VersionKey{
+							System:  depsdevpb.System_GO,
+							Name:    "github.com/ossf/scorecard/v5",
+							Version: "v5.0.0",
+						},
+						SlsaProvenances: []*depsdevpb.SLSAProvenance{
+							{
+								SourceRepository: "https://github.com/ossf/scorecard",
+								Commit:           "ea7e27ed41b76ab879c862fa0ca4cc9c61764ee4",
+								Verified:         true,
+							},
+						},
+						RelationType:       depsdevpb.ProjectRelationType_SOURCE_REPO,
+						RelationProvenance: depsdevpb.ProjectRelationProvenance_GO_ORIGIN,
+					},
+				},
+			},
+			"github.com/ossf/empty": {},
+		},
+	})
+
+	tests := []struct {
+		want    *packageclient.ProjectPackageVersions
+		wantErr error
+		name    string
+		project string
+	}{
+		{
+			name:    "versions are mapped",
+			project: "OSSF/Scorecard",
+			want: &packageclient.ProjectPackageVersions{
+				Versions: []packageclient.PackageVersion{
+					{
+						VersionKey: packageclient.VersionKey{
+							System:  "GO",
+							Name:    "github.com/ossf/scorecard/v5",
+							Version: "v5.0.0",
+						},
+						SLSAProvenances: []packageclient.SLSAProvenance{
+							{
+								SourceRepository: "https://github.com/ossf/scorecard",
+								Commit:           "ea7e27ed41b76ab879c862fa0ca4cc9c61764ee4",
+								Verified:         true,
+							},
+						},
+						RelationType:       "SOURCE_REPO",
+						RelationProvenance: "GO_ORIGIN",
+					},
+				},
+			},
+		},
+		{
+			name:    "not found",
+			project: "ossf/missing",
+			wantErr: packageclient.ErrProjNotFoundInDepsDev,
+		},
+		{
+			name:    "no versions",
+			project: "ossf/empty",
+			wantErr: packageclient.ErrEmptyResponse,
+		},
+		{
+			name:    "other errors",
+			project: "ossf/unavailable",
+			wantErr: packageclient.ErrDepsDevAPI,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := c.GetProjectPackageVersions(context.Background(), "github.com", tt.project)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// sharedLimiter is the rate limiter shared by all requests of a client. On
+// top of the steady rate, deps.dev can pause it with a 429 and Retry-After,
+// so every caller backs off rather than only the request which was limited.
+type sharedLimiter struct {
+	pausedUntil time.Time
+	limiter     *rate.Limiter
+	mu          sync.Mutex
+}
+
+func newSharedLimiter(limit rate.Limit, burst int) *sharedLimiter {
+	return &sharedLimiter{
+		limiter: rate.NewLimiter(limit, burst),
+	}
+}
+
+// Wait blocks until the limiter is no longer paused and then until the rate
+// limit allows a request, or ctx is done.
+func (s *sharedLimiter) Wait(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		pause := time.Until(s.pausedUntil)
+		s.mu.Unlock()
+		if pause <= 0 {
+			break
+		}
+		// the pause may be extended while waiting, so check again afterwards
+		timer := time.NewTimer(pause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("paused by Retry-After: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+	if err := s.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("limiter.Wait: %w", err)
+	}
+	return nil
+}
+
+// PauseFor holds back all requests for d. Overlapping pauses don't shorten
+// one another.
+func (s *sharedLimiter) PauseFor(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until := time.Now().Add(d); until.After(s.pausedUntil) {
+		s.pausedUntil = until
+	}
+}
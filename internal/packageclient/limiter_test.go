@@ -0,0 +1,91 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestSharedLimiter_pause(t *testing.T) {
+	t.Parallel()
+	l := newSharedLimiter(rate.Inf, 1)
+	l.PauseFor(100 * time.Millisecond)
+	// a shorter pause doesn't cut the longer one short
+	l.PauseFor(time.Millisecond)
+
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected Wait to be paused for 100ms, returned after %v", elapsed)
+	}
+
+	l.PauseFor(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestGetProjectPackageVersions_retryAfterPausesClient(t *testing.T) {
+	t.Parallel()
+	var (
+		mu    sync.Mutex
+		calls = map[string]time.Time{}
+	)
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		mu.Lock()
+		calls[r.URL.Path] = time.Now()
+		mu.Unlock()
+		if strings.Contains(r.URL.Path, "limited") {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": []string{"1"}},
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    r,
+			}, nil
+		}
+		return okResponse(r), nil
+	})
+	client := CreateDepsDevClientWithOptions(WithTransport(transport), WithMaxAttempts(1))
+
+	if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/limited"); err == nil {
+		t.Fatal("expected an error for the rate limited project")
+	}
+	if _, err := client.GetProjectPackageVersions(context.Background(), "github.com", "ossf/scorecard"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	limited, next := calls["/v3/projects/github.com/ossf/limited:packageversions"], calls["/v3/projects/github.com/ossf/scorecard:packageversions"]
+	if limited.IsZero() || next.IsZero() {
+		t.Fatalf("expected both projects to be requested, got %v", calls)
+	}
+	if gap := next.Sub(limited); gap < time.Second {
+		t.Errorf("expected other requests to wait for Retry-After, next request after %v", gap)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrAmbiguousPackage is returned when a repository publishes more than
+	// one package, so it can't be mapped to a single one.
+	ErrAmbiguousPackage = errors.New("repository maps to multiple packages")
+	// ErrInvalidRepoURI is returned for repository URIs without a host and path.
+	ErrInvalidRepoURI = errors.New("invalid repository URI")
+)
+
+// ResolvePackageForRepo returns the name and system of the package published
+// from repoURI, e.g. "https://github.com/ossf/scorecard" or
+// "github.com/ossf/scorecard". Only packages which name the repository as
+// their source are considered, and a non-empty system restricts them to that
+// system. Versions of the same package are collapsed, as are the major
+// version paths of a Go module (e.g. ".../v4" and ".../v5"), in which case the
+// latest major version is returned. ErrAmbiguousPackage is returned if the
+// repository publishes several distinct packages, and ErrEmptyResponse if it
+// publishes none.
+func ResolvePackageForRepo(
+	ctx context.Context, c ProjectPackageClient, repoURI, system string,
+) (packageName, packageSystem string, err error) {
+	host, project, err := splitRepoURI(repoURI)
+	if err != nil {
+		return "", "", err
+	}
+	versions, err := GetProjectPackageVersionsForSystem(ctx, c, host, project, system)
+	if err != nil {
+		return "", "", fmt.Errorf("ResolvePackageForRepo: %w", err)
+	}
+
+	var (
+		base  string
+		major int
+	)
+	for _, v := range versions.Versions {
+		if v.RelationType != sourceRepoRelation {
+			continue
+		}
+		name, vSystem := v.VersionKey.Name, v.VersionKey.System
+		vBase, vMajor := name, 1
+		if vSystem == goSystem {
+			vBase, vMajor = splitGoMajorVersion(name)
+		}
+		switch {
+		case packageName == "":
+			packageName, packageSystem, base, major = name, vSystem, vBase, vMajor
+		case vSystem != packageSystem || vBase != base:
+			return "", "", fmt.Errorf("ResolvePackageForRepo: %w: %s %s and %s %s",
+				ErrAmbiguousPackage, packageSystem, packageName, vSystem, name)
+		case vMajor > major:
+			packageName, major = name, vMajor
+		}
+	}
+	if packageName == "" {
+		return "", "", fmt.Errorf("ResolvePackageForRepo: no source repository packages: %w", ErrEmptyResponse)
+	}
+	return packageName, packageSystem, nil
+}
+
+const (
+	// sourceRepoRelation is the relation type of packages published from a project.
+	sourceRepoRelation = "SOURCE_REPO"
+	goSystem           = "GO"
+)
+
+// splitGoMajorVersion splits a Go module path into the path without its major
+// version suffix and the major version, e.g. "example.com/mod/v5" into
+// "example.com/mod" and 5. Paths without a suffix are major version 1.
+func splitGoMajorVersion(path string) (string, int) {
+	i := strings.LastIndex(path, "/v")
+	if i < 0 {
+		return path, 1
+	}
+	major, err := strconv.Atoi(path[i+len("/v"):])
+	if err != nil || major < 2 || strings.HasPrefix(path[i+len("/v"):], "0") {
+		return path, 1
+	}
+	return path[:i], major
+}
+
+// splitRepoURI splits a repository URI, with or without a scheme, into its
+// host and project path.
+func splitRepoURI(repoURI string) (host, project string, err error) {
+	if !strings.Contains(repoURI, "://") {
+		repoURI = "https://" + repoURI
+	}
+	u, err := url.Parse(repoURI)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidRepoURI, err)
+	}
+	project = strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if u.Host == "" || project == "" {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidRepoURI, repoURI)
+	}
+	return u.Host, project, nil
+}
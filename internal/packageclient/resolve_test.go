@@ -0,0 +1,195 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const (
+	scorecardSourceVersions = `{"versions":[
+	{"versionKey":{"system":"GO","name":"github.com/ossf/scorecard","version":"v1.0.0"},"relationType":"SOURCE_REPO"}
+]}`
+	goMajorVersions = `{"versions":[
+	{"versionKey":{"system":"GO","name":"github.com/ossf/scorecard/v4","version":"v4.13.1"},"relationType":"SOURCE_REPO"},
+	{"versionKey":{"system":"GO","name":"github.com/ossf/scorecard/v5","version":"v5.0.0"},"relationType":"SOURCE_REPO"},
+	{"versionKey":{"system":"GO","name":"github.com/ossf/scorecard","version":"v1.2.0"},"relationType":"SOURCE_REPO"}
+]}`
+	goSubmoduleVersions = `{"versions":[
+	{"versionKey":{"system":"GO","name":"github.com/example/example/v2","version":"v2.0.0"},"relationType":"SOURCE_REPO"},
+	{"versionKey":{"system":"GO","name":"github.com/example/example/tools","version":"v0.1.0"},"relationType":"SOURCE_REPO"}
+]}`
+	mixedRelationVersions = `{"versions":[
+	{"versionKey":{"system":"NPM","name":"example","version":"1.0.0"},"relationType":"SOURCE_REPO"},
+	{"versionKey":{"system":"NPM","name":"example-fork","version":"1.0.0"},"relationType":"ISSUE_TRACKER"},
+	{"versionKey":{"system":"PYPI","name":"example-docs","version":"1.0.0"},"relationType":"HOMEPAGE"}
+]}`
+	multiSystemSourceVersions = `{"versions":[
+	{"versionKey":{"system":"NPM","name":"example","version":"1.0.0"},"relationType":"SOURCE_REPO"},
+	{"versionKey":{"system":"GO","name":"github.com/example/example","version":"v1.0.0"},"relationType":"SOURCE_REPO"},
+	{"versionKey":{"system":"NPM","name":"example","version":"1.1.0"},"relationType":"SOURCE_REPO"}
+]}`
+)
+
+func TestResolvePackageForRepo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		wantErr    error
+		name       string
+		repoURI    string
+		system     string
+		response   string
+		wantHost   string
+		wantPath   string
+		wantName   string
+		wantSystem string
+	}{
+		{
+			name:       "single package",
+			repoURI:    "https://github.com/ossf/scorecard",
+			response:   scorecardSourceVersions,
+			wantHost:   "github.com",
+			wantPath:   "ossf/scorecard",
+			wantName:   "github.com/ossf/scorecard",
+			wantSystem: "GO",
+		},
+		{
+			name:       "no scheme and .git suffix",
+			repoURI:    "github.com/ossf/scorecard.git/",
+			response:   scorecardSourceVersions,
+			wantHost:   "github.com",
+			wantPath:   "ossf/scorecard",
+			wantName:   "github.com/ossf/scorecard",
+			wantSystem: "GO",
+		},
+		{
+			name:       "go major versions",
+			repoURI:    "github.com/ossf/scorecard",
+			response:   goMajorVersions,
+			wantHost:   "github.com",
+			wantPath:   "ossf/scorecard",
+			wantName:   "github.com/ossf/scorecard/v5",
+			wantSystem: "GO",
+		},
+		{
+			name:     "go modules with different paths",
+			repoURI:  "github.com/example/example",
+			response: goSubmoduleVersions,
+			wantErr:  ErrAmbiguousPackage,
+		},
+		{
+			name:       "only source repo relations",
+			repoURI:    "github.com/example/example",
+			response:   mixedRelationVersions,
+			wantHost:   "github.com",
+			wantPath:   "example/example",
+			wantName:   "example",
+			wantSystem: "NPM",
+		},
+		{
+			name:     "no source repo relations",
+			repoURI:  "github.com/example/example",
+			system:   "pypi",
+			response: mixedRelationVersions,
+			wantErr:  ErrEmptyResponse,
+		},
+		{
+			name:     "multiple packages",
+			repoURI:  "github.com/example/example",
+			response: multiSystemSourceVersions,
+			wantErr:  ErrAmbiguousPackage,
+		},
+		{
+			name:       "system selects one package",
+			repoURI:    "github.com/example/example",
+			system:     "npm",
+			response:   multiSystemSourceVersions,
+			wantHost:   "github.com",
+			wantPath:   "example/example",
+			wantName:   "example",
+			wantSystem: "NPM",
+		},
+		{
+			name:     "unknown system",
+			repoURI:  "github.com/example/example",
+			system:   "cpan",
+			response: multiSystemSourceVersions,
+			wantErr:  ErrUnknownSystem,
+		},
+		{
+			name:     "no packages",
+			repoURI:  "github.com/example/example",
+			response: `{"versions":[]}`,
+			wantErr:  ErrEmptyResponse,
+		},
+		{
+			name:    "missing project",
+			repoURI: "https://github.com",
+			wantErr: ErrInvalidRepoURI,
+		},
+	}
+	for _, tt := range tests {
+		var gotHost, gotPath string
+		client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+			gotHost, gotPath = host, project
+			var v ProjectPackageVersions
+			if err := json.Unmarshal([]byte(tt.response), &v); err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+			return &v, nil
+		})
+		name, system, err := ResolvePackageForRepo(context.Background(), client, tt.repoURI, tt.system)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if gotHost != tt.wantHost || gotPath != tt.wantPath {
+			t.Errorf("%s: expected lookup of %s %s, got %s %s", tt.name, tt.wantHost, tt.wantPath, gotHost, gotPath)
+		}
+		if name != tt.wantName || system != tt.wantSystem {
+			t.Errorf("%s: expected %s %s, got %s %s", tt.name, tt.wantSystem, tt.wantName, system, name)
+		}
+	}
+}
+
+func TestSplitGoMajorVersion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		path      string
+		wantBase  string
+		wantMajor int
+	}{
+		{path: "github.com/ossf/scorecard", wantBase: "github.com/ossf/scorecard", wantMajor: 1},
+		{path: "github.com/ossf/scorecard/v5", wantBase: "github.com/ossf/scorecard", wantMajor: 5},
+		{path: "github.com/ossf/scorecard/v12", wantBase: "github.com/ossf/scorecard", wantMajor: 12},
+		{path: "github.com/example/v1", wantBase: "github.com/example/v1", wantMajor: 1},
+		{path: "github.com/example/v02", wantBase: "github.com/example/v02", wantMajor: 1},
+		{path: "github.com/example/vendor", wantBase: "github.com/example/vendor", wantMajor: 1},
+	}
+	for _, tt := range tests {
+		base, major := splitGoMajorVersion(tt.path)
+		if base != tt.wantBase || major != tt.wantMajor {
+			t.Errorf("splitGoMajorVersion(%q) = %q, %d, want %q, %d", tt.path, base, major, tt.wantBase, tt.wantMajor)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownSystem is returned for package systems deps.dev doesn't support.
+var ErrUnknownSystem = errors.New("unknown deps.dev package system")
+
+// knownSystems are the package systems supported by the deps.dev v3 API.
+var knownSystems = map[string]bool{
+	"GO":       true,
+	"RUBYGEMS": true,
+	"NPM":      true,
+	"CARGO":    true,
+	"MAVEN":    true,
+	"PYPI":     true,
+	"NUGET":    true,
+}
+
+// ValidateSystem returns the canonical, upper-case form of system, e.g. "NPM"
+// for "npm", or ErrUnknownSystem if deps.dev doesn't support it.
+func ValidateSystem(system string) (string, error) {
+	canonical := strings.ToUpper(strings.TrimSpace(system))
+	if !knownSystems[canonical] {
+		return "", fmt.Errorf("%w: %q", ErrUnknownSystem, system)
+	}
+	return canonical, nil
+}
+
+// GetProjectPackageVersionsForSystem is like c.GetProjectPackageVersions, but
+// only keeps the versions published to system, e.g. "NPM" or "GO". This matters
+// for projects that publish to several ecosystems. An empty system keeps every
+// version. If no version matches, ErrEmptyResponse is returned, and if system
+// isn't a known deps.dev system, ErrUnknownSystem.
+func GetProjectPackageVersionsForSystem(
+	ctx context.Context, c ProjectPackageClient, host, project, system string,
+) (*ProjectPackageVersions, error) {
+	if system != "" {
+		var err error
+		if system, err = ValidateSystem(system); err != nil {
+			return nil, err
+		}
+	}
+	versions, err := c.GetProjectPackageVersions(ctx, host, project)
+	if err != nil || system == "" {
+		return versions, err //nolint:wrapcheck
+	}
+	var res ProjectPackageVersions
+	for _, v := range versions.Versions {
+		if strings.EqualFold(v.VersionKey.System, system) {
+			res.Versions = append(res.Versions, v)
+		}
+	}
+	if len(res.Versions) == 0 {
+		return nil, fmt.Errorf("GetProjectPackageVersionsForSystem: no %s versions: %w", system, ErrEmptyResponse)
+	}
+	return &res, nil
+}
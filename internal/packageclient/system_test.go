@@ -0,0 +1,123 @@
+// Copyright 2024 OpenSSF Scorecard Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packageclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const multiSystemVersions = `{"versions":[
+	{"versionKey":{"system":"NPM","name":"example","version":"1.0.0"}},
+	{"versionKey":{"system":"GO","name":"github.com/example/example","version":"v1.0.0"}},
+	{"versionKey":{"system":"NPM","name":"example","version":"1.1.0"}}
+]}`
+
+func TestGetProjectPackageVersionsForSystem(t *testing.T) {
+	t.Parallel()
+	client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		var v ProjectPackageVersions
+		if err := json.Unmarshal([]byte(multiSystemVersions), &v); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		return &v, nil
+	})
+
+	tests := []struct {
+		wantErr error
+		name    string
+		system  string
+		want    int
+	}{
+		{
+			name:   "unset",
+			system: "",
+			want:   3,
+		},
+		{
+			name:   "npm",
+			system: "NPM",
+			want:   2,
+		},
+		{
+			name:   "case insensitive",
+			system: "go",
+			want:   1,
+		},
+		{
+			name:    "no match",
+			system:  "MAVEN",
+			wantErr: ErrEmptyResponse,
+		},
+		{
+			name:    "unknown system",
+			system:  "nmp",
+			wantErr: ErrUnknownSystem,
+		},
+	}
+	for _, tt := range tests {
+		got, err := GetProjectPackageVersionsForSystem(context.Background(), client, "github.com", "example/example", tt.system)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if len(got.Versions) != tt.want {
+			t.Errorf("%s: expected %d versions, got %d", tt.name, tt.want, len(got.Versions))
+		}
+	}
+}
+
+func TestGetProjectPackageVersionsForSystem_error(t *testing.T) {
+	t.Parallel()
+	client := stubProjectClient(func(ctx context.Context, host, project string) (*ProjectPackageVersions, error) {
+		return nil, ErrProjNotFoundInDepsDev
+	})
+	_, err := GetProjectPackageVersionsForSystem(context.Background(), client, "github.com", "example/example", "NPM")
+	if !errors.Is(err, ErrProjNotFoundInDepsDev) {
+		t.Errorf("expected ErrProjNotFoundInDepsDev, got %v", err)
+	}
+}
+
+func TestValidateSystem(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		wantErr error
+		system  string
+		want    string
+	}{
+		{system: "GO", want: "GO"},
+		{system: "npm", want: "NPM"},
+		{system: " PyPI ", want: "PYPI"},
+		{system: "RubyGems", want: "RUBYGEMS"},
+		{system: "nmp", wantErr: ErrUnknownSystem},
+		{system: "", wantErr: ErrUnknownSystem},
+	}
+	for _, tt := range tests {
+		got, err := ValidateSystem(tt.system)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateSystem(%q): expected error %v, got %v", tt.system, tt.wantErr, err)
+		}
+		if got != tt.want {
+			t.Errorf("ValidateSystem(%q) = %q, want %q", tt.system, got, tt.want)
+		}
+	}
+}
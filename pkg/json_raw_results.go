@@ -264,6 +264,8 @@ type jsonRawResults struct {
 	DatabaseVulnerabilities []jsonDatabaseVulnerability `json:"databaseVulnerabilities"`
 	// List of binaries found in the repo.
 	Binaries []jsonFile `json:"binaries"`
+	// List of binaries found in the repo but ignored by configuration.
+	IgnoredBinaries []jsonFile `json:"ignoredBinaries,omitempty"`
 	// List of security policy files found in the repo.
 	// Note: we return one at most.
 	SecurityPolicies []jsonSecurityFile `json:"securityPolicies"`
@@ -633,6 +635,11 @@ func (r *jsonScorecardRawResult) addBinaryArtifactRawResults(ba *checker.BinaryA
 			Path: v.Path,
 		})
 	}
+	for _, v := range ba.IgnoredFiles {
+		r.Results.IgnoredBinaries = append(r.Results.IgnoredBinaries, jsonFile{
+			Path: v.Path,
+		})
+	}
 	return nil
 }
 
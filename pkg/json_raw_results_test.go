@@ -15,7 +15,9 @@ package pkg
 
 import (
 	"bytes"
+	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -834,6 +836,70 @@ func TestAddBinaryArtifactRawResults(t *testing.T) {
 	}
 }
 
+func TestAddBinaryArtifactRawResults_ignored(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		ignored  []checker.File
+		expected []jsonFile
+	}{
+		{
+			name: "no ignored binaries",
+		},
+		{
+			name: "ignored binaries",
+			ignored: []checker.File{
+				{
+					Path: "assets/font.ttf",
+				},
+				{
+					Path: "assets/icons/logo.ico",
+				},
+			},
+			expected: []jsonFile{
+				{
+					Path: "assets/font.ttf",
+				},
+				{
+					Path: "assets/icons/logo.ico",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := &jsonScorecardRawResult{}
+			ba := &checker.BinaryArtifactData{
+				Files: []checker.File{
+					{
+						Path: "bin/tool.exe",
+					},
+				},
+				IgnoredFiles: tt.ignored,
+			}
+			if err := r.addBinaryArtifactRawResults(ba); err != nil {
+				t.Fatalf("addBinaryArtifactRawResults returned an error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, r.Results.IgnoredBinaries); diff != "" {
+				t.Errorf("mismatch in ignored binaries (-want +got):\n%s", diff)
+			}
+			if len(r.Results.Binaries) != 1 {
+				t.Errorf("ignored binaries changed the reported binaries: %v", r.Results.Binaries)
+			}
+
+			b, err := json.Marshal(r.Results)
+			if err != nil {
+				t.Fatalf("json.Marshal: %v", err)
+			}
+			if got, want := strings.Contains(string(b), `"ignoredBinaries"`), len(tt.expected) > 0; got != want {
+				t.Errorf("ignoredBinaries present in JSON = %v, want %v: %s", got, want, b)
+			}
+		})
+	}
+}
+
 func TestAddSecurityPolicyRawResults(t *testing.T) {
 	t.Parallel()
 	r := &jsonScorecardRawResult{}